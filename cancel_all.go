@@ -0,0 +1,367 @@
+package robinhood
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// AssetClass identifies the kind of order a bulk operation applies to.
+type AssetClass string
+
+// Well-known asset classes.
+const (
+	AssetEquity AssetClass = "equity"
+	AssetCrypto AssetClass = "crypto"
+	AssetOption AssetClass = "option"
+)
+
+// openOrderStates are the states in which an order may still fill.
+var openOrderStates = []string{
+	OrderStateQueued,
+	OrderStateUnconfirmed,
+	OrderStateConfirmed,
+	OrderStatePartiallyFilled,
+}
+
+// CancelFilter selects the open orders cancelled by CancelAllOpenOrders.
+// Zero-valued fields match every order.
+type CancelFilter struct {
+	Assets []AssetClass
+	// Symbol matches an equity symbol, a crypto asset code or pair symbol
+	// ("BTC" or "BTC-USD"), or an option chain symbol.
+	Symbol string
+	// Side is "buy" or "sell". An options order matches if any leg does.
+	Side string
+	// Type is "market" or "limit".
+	Type          string
+	CreatedBefore time.Time
+	// Concurrency bounds the number of cancellations in flight. The client
+	// throttles the requests themselves. Defaults to 4.
+	Concurrency int
+}
+
+func (f CancelFilter) wants(a AssetClass) bool {
+	if len(f.Assets) == 0 {
+		return true
+	}
+	for _, x := range f.Assets {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (f CancelFilter) matches(symbols []string, sides []string, typ string, created time.Time) bool {
+	if f.Symbol != "" && !containsFold(symbols, f.Symbol) {
+		return false
+	}
+	if f.Side != "" && !containsFold(sides, f.Side) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(f.Type, typ) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !created.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, x := range ss {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelResult is the outcome of cancelling a single order.
+type CancelResult struct {
+	Asset AssetClass
+	ID    string
+	// Symbol is left empty for equity orders unless the filter selects by
+	// symbol, since looking it up costs a request per instrument.
+	Symbol    string
+	Side      string
+	Type      string
+	CreatedAt time.Time
+	// State is the order's state as last seen. When a cancellation is
+	// refused because the order already finished (e.g. it filled), Err is
+	// nil and State holds the final state.
+	State     string
+	Cancelled bool
+	Err       error
+}
+
+// cancelTarget is an open order awaiting cancellation. A target with no
+// cancel func is only reported, with its Err already set.
+type cancelTarget struct {
+	CancelResult
+	cancel  func(context.Context) error
+	refresh func(context.Context) (string, error)
+}
+
+// CancelAllOpenOrders cancels every open equity, crypto and options order
+// matching the filter, concurrently, and reports the result for each one. An
+// error is returned only if the open orders could not be listed; failures to
+// cancel individual orders are reported in their results. An equity order
+// whose symbol cannot be looked up to check against filter.Symbol is not
+// cancelled, and is reported with the lookup error.
+func (c *Client) CancelAllOpenOrders(ctx context.Context, filter CancelFilter) ([]CancelResult, error) {
+	var ts []*cancelTarget
+
+	if filter.wants(AssetEquity) {
+		eq, err := c.openEquityTargets(ctx, filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list open orders")
+		}
+		ts = append(ts, eq...)
+	}
+
+	if filter.wants(AssetCrypto) {
+		cr, err := c.openCryptoTargets(ctx, filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list open crypto orders")
+		}
+		ts = append(ts, cr...)
+	}
+
+	if filter.wants(AssetOption) {
+		op, err := c.openOptionTargets(ctx, filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list open options orders")
+		}
+		ts = append(ts, op...)
+	}
+
+	n := filter.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(n)
+	for _, t := range ts {
+		t := t
+		eg.Go(func() error {
+			t.run(ctx)
+			return nil
+		})
+	}
+	eg.Wait()
+
+	out := make([]CancelResult, len(ts))
+	for i, t := range ts {
+		out[i] = t.CancelResult
+	}
+	return out, nil
+}
+
+func (t *cancelTarget) run(ctx context.Context) {
+	if t.cancel == nil {
+		return
+	}
+
+	err := t.cancel(ctx)
+	if err == nil {
+		t.Cancelled = true
+		return
+	}
+
+	// The order may have finished on its own in the meantime, in which
+	// case there is nothing left to cancel.
+	state, rerr := t.refresh(ctx)
+	if rerr == nil {
+		t.State = state
+		if isDoneState(state) {
+			return
+		}
+	}
+	t.Err = err
+}
+
+// pageOpen calls list for each open order state, following pagination, until
+// every page has been read.
+func pageOpen(list func(next *string, state string) (string, error)) error {
+	for _, state := range openOrderStates {
+		var next *string
+		for {
+			n, err := list(next, state)
+			if err != nil {
+				return err
+			}
+			if n == "" {
+				break
+			}
+			next = &n
+		}
+	}
+	return nil
+}
+
+func (c *Client) openEquityTargets(ctx context.Context, f CancelFilter) ([]*cancelTarget, error) {
+	symbols := map[string]string{}
+	symbolFor := func(instURL string) (string, error) {
+		if s, ok := symbols[instURL]; ok {
+			return s, nil
+		}
+		inst, err := c.GetInstrument(ctx, instURL)
+		if err != nil {
+			return "", err
+		}
+		symbols[instURL] = inst.Symbol
+		return inst.Symbol, nil
+	}
+
+	var ts []*cancelTarget
+	err := pageOpen(func(next *string, state string) (string, error) {
+		page, n, err := c.GetOrders(ctx, next, 100, state)
+		if err != nil {
+			return "", err
+		}
+		for _, o := range page {
+			o := o
+			res := CancelResult{
+				Asset:     AssetEquity,
+				ID:        o.ID,
+				Side:      o.Side,
+				Type:      o.Type,
+				CreatedAt: o.CreatedAt,
+				State:     o.State,
+			}
+
+			// Equity orders only carry an instrument URL, so the symbol
+			// costs a request and is only looked up to filter on.
+			if f.Symbol != "" {
+				sym, err := symbolFor(o.Instrument)
+				if err != nil {
+					// Rather than leave a possible match working
+					// unnoticed, report it if the rest of the filter
+					// matches.
+					rest := f
+					rest.Symbol = ""
+					if rest.matches(nil, []string{o.Side}, o.Type, o.CreatedAt) {
+						res.Err = errors.Wrap(err, "could not look up symbol")
+						ts = append(ts, &cancelTarget{CancelResult: res})
+					}
+					continue
+				}
+				res.Symbol = sym
+			}
+			if !f.matches([]string{res.Symbol}, []string{o.Side}, o.Type, o.CreatedAt) {
+				continue
+			}
+			ts = append(ts, &cancelTarget{
+				CancelResult: res,
+				cancel: func(ctx context.Context) error {
+					return c.CancelOrderById(ctx, o.ID)
+				},
+				refresh: func(ctx context.Context) (string, error) {
+					err := o.Update(ctx, c)
+					return o.State, err
+				},
+			})
+		}
+		return n, nil
+	})
+	return ts, err
+}
+
+func (c *Client) openCryptoTargets(ctx context.Context, f CancelFilter) ([]*cancelTarget, error) {
+	pairs, err := c.GetCryptoCurrencyPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]CryptoCurrencyPair{}
+	for _, p := range pairs {
+		byID[p.ID] = p
+	}
+
+	var ts []*cancelTarget
+	err = pageOpen(func(next *string, state string) (string, error) {
+		page, n, err := c.GetCryptoOrders(ctx, next, 100, state)
+		if err != nil {
+			return "", err
+		}
+		for _, o := range page {
+			o := o
+			p := byID[o.CurrencyPairID]
+			syms := []string{p.Symbol, p.CyrptoAssetCurrency.Code}
+			if !f.matches(syms, []string{o.Side}, o.Type, o.CreatedAt) {
+				continue
+			}
+			ts = append(ts, &cancelTarget{
+				CancelResult: CancelResult{
+					Asset:     AssetCrypto,
+					ID:        o.ID,
+					Symbol:    p.Symbol,
+					Side:      o.Side,
+					Type:      o.Type,
+					CreatedAt: o.CreatedAt,
+					State:     o.State,
+				},
+				cancel: func(ctx context.Context) error {
+					return c.CancelCryptoOrderById(ctx, o.ID)
+				},
+				refresh: func(ctx context.Context) (string, error) {
+					err := o.Update(ctx, c)
+					return o.State, err
+				},
+			})
+		}
+		return n, nil
+	})
+	return ts, err
+}
+
+func (c *Client) openOptionTargets(ctx context.Context, f CancelFilter) ([]*cancelTarget, error) {
+	var ts []*cancelTarget
+	err := pageOpen(func(next *string, state string) (string, error) {
+		page, n, err := c.GetOptionsOrders(ctx, next, 100, OptionOrderFilter{State: state})
+		if err != nil {
+			return "", err
+		}
+
+		for _, o := range page {
+			o := o
+			sides := make([]string, len(o.Legs))
+			for i, l := range o.Legs {
+				sides[i] = l.Side
+			}
+			if !f.matches([]string{o.ChainSymbol}, sides, o.Type, o.CreatedAt) {
+				continue
+			}
+
+			side := ""
+			if len(sides) == 1 {
+				side = sides[0]
+			}
+			ts = append(ts, &cancelTarget{
+				CancelResult: CancelResult{
+					Asset:     AssetOption,
+					ID:        o.ID,
+					Symbol:    o.ChainSymbol,
+					Side:      side,
+					Type:      o.Type,
+					CreatedAt: o.CreatedAt,
+					State:     o.State,
+				},
+				cancel: func(ctx context.Context) error {
+					return o.Cancel(ctx, c)
+				},
+				refresh: func(ctx context.Context) (string, error) {
+					err := o.Update(ctx, c)
+					return o.State, err
+				},
+			})
+		}
+		return n, nil
+	})
+	return ts, err
+}
@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -36,6 +38,7 @@ const (
 	EPOptions             = EPBase + "options/"
 	EPMarket              = EPBase + "marketdata/"
 	EPOptionQuote         = EPMarket + "options/"
+	EPMarkets             = EPBase + "markets/"
 
 	EPHistPortfolio = EPHistory + "portfolio/"
 
@@ -52,6 +55,20 @@ type Client struct {
 	CryptoAccount *CryptoAccount
 	*http.Client
 
+	// DryRun, when set, causes order-submitting and order-cancelling methods
+	// to log their payloads instead of sending them. Read-only calls are
+	// unaffected.
+	DryRun bool
+	// Logger receives dry-run output. If nil, the standard logger is used.
+	Logger *log.Logger
+	// RefJournal, if set, records the ref ID of every order before it is
+	// submitted. See SubmitOrder.
+	RefJournal RefJournal
+	// Journal, if set, receives an entry for every order submitted,
+	// replaced or cancelled through the client.
+	Journal Journal
+
+	mu       sync.Mutex
 	lastCall time.Time
 }
 
@@ -135,12 +152,7 @@ func (e ErrorMap) Error() string {
 // issues.
 func (c *Client) DoAndDecode(ctx context.Context, req *http.Request, dest interface{}) error {
 
-	// api-throttle
-	df := time.Now().Sub(c.lastCall)
-	if df.Milliseconds() < apiWaitTime {
-		time.Sleep(time.Duration(apiWaitTime-df.Milliseconds()) * time.Millisecond)
-	}
-	c.lastCall = time.Now()
+	c.throttle()
 
 	req.Header.Add("x-robinhood-api-version", RHApiVersion)
 	
@@ -163,6 +175,19 @@ func (c *Client) DoAndDecode(ctx context.Context, req *http.Request, dest interf
 	return json.NewDecoder(res.Body).Decode(dest)
 }
 
+// throttle spaces out API calls by at least apiWaitTime milliseconds. Callers
+// on other goroutines wait their turn.
+func (c *Client) throttle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	df := time.Now().Sub(c.lastCall)
+	if df.Milliseconds() < apiWaitTime {
+		time.Sleep(time.Duration(apiWaitTime-df.Milliseconds()) * time.Millisecond)
+	}
+	c.lastCall = time.Now()
+}
+
 // Meta holds metadata common to many RobinHood types.
 type Meta struct {
 	CreatedAt time.Time `json:"created_at"`
@@ -0,0 +1,33 @@
+package robinhood
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newTestClient returns a client whose requests, whatever their endpoint,
+// are served by h.
+func newTestClient(t *testing.T, h http.Handler) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{Client: &http.Client{Transport: rewriteTransport{u}}}
+}
+
+// rewriteTransport sends every request to the host of its URL.
+type rewriteTransport struct {
+	*url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.Scheme
+	req.URL.Host = rt.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
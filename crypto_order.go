@@ -35,34 +35,29 @@ type CryptoOrder struct {
 
 // CryptoOrderOutput holds the response from api
 type CryptoOrderOutput struct {
-	AccountID          string    `json:"account_id"`
-	AveragePrice       float64   `json:"average_price,string"`
-	CancelURL          string    `json:"cancel_url"`
-	CreatedAt          time.Time `json:"created_at"`
-	CumulativeQuantity float64   `json:"cumulative_quantity,string"`
-	CurrencyPairID     string    `json:"currency_pair_id"`
-	EnteredPrice       float64   `json:"entered_price,string"`
-	Executions         []struct {
-		EffectivePrice float64   `json:"effective_price,string"`
-		ID             string    `json:"id"`
-		Quantity       float64   `json:"quantity,string"`
-		Timestamp      time.Time `json:"timestamp"`
-	} `json:"executions"`
-	ID                      string      `json:"id"`
-	InitiatorID             interface{} `json:"initiator_id"`
-	InitiatorType           interface{} `json:"initiator_type"`
-	LastTransactionAt       time.Time   `json:"last_transaction_at"`
-	Price                   float64     `json:"price,string"`
-	Quantity                float64     `json:"quantity,string"`
-	RejectReason            string      `json:"reject_reason"`
-	RefID                   string      `json:"ref_id"`
-	RoundedExecutedNotional float64     `json:"rounded_executed_notional,string"`
-	Side                    string      `json:"side"`
-	State                   string      `json:"state"`
-	StopPrice               float64     `json:"stop_price,string"`
-	TimeInForce             string      `json:"time_in_force"`
-	Type                    string      `json:"type"`
-	UpdatedAt               time.Time   `json:"updated_at"`
+	AccountID               string            `json:"account_id"`
+	AveragePrice            float64           `json:"average_price,string"`
+	CancelURL               string            `json:"cancel_url"`
+	CreatedAt               time.Time         `json:"created_at"`
+	CumulativeQuantity      float64           `json:"cumulative_quantity,string"`
+	CurrencyPairID          string            `json:"currency_pair_id"`
+	EnteredPrice            float64           `json:"entered_price,string"`
+	Executions              []CryptoExecution `json:"executions"`
+	ID                      string            `json:"id"`
+	InitiatorID             interface{}       `json:"initiator_id"`
+	InitiatorType           interface{}       `json:"initiator_type"`
+	LastTransactionAt       time.Time         `json:"last_transaction_at"`
+	Price                   float64           `json:"price,string"`
+	Quantity                float64           `json:"quantity,string"`
+	RejectReason            string            `json:"reject_reason"`
+	RefID                   string            `json:"ref_id"`
+	RoundedExecutedNotional float64           `json:"rounded_executed_notional,string"`
+	Side                    string            `json:"side"`
+	State                   string            `json:"state"`
+	StopPrice               float64           `json:"stop_price,string"`
+	TimeInForce             string            `json:"time_in_force"`
+	Type                    string            `json:"type"`
+	UpdatedAt               time.Time         `json:"updated_at"`
 }
 
 func (c *Client) CreateCryptoOrder(currId string) *CryptoOrder {
@@ -80,7 +75,7 @@ func (c *Client) CreateCryptoOrder(currId string) *CryptoOrder {
 }
 
 // CryptoOrder will actually place the order
-func (c *Client) SubmitCryptoOrder(ctx context.Context, o *CryptoOrder) (*CryptoOrderOutput, error) {
+func (c *Client) SubmitCryptoOrder(ctx context.Context, o *CryptoOrder) (out *CryptoOrderOutput, err error) {
 
 	if o.Quantity == 0 {
 		o.Quantity = math.Round(o.AmountInDollars / o.Price)
@@ -91,6 +86,21 @@ func (c *Client) SubmitCryptoOrder(ctx context.Context, o *CryptoOrder) (*Crypto
 		return nil, err
 	}
 
+	defer func() {
+		c.journal(ctx, JournalEntry{
+			Action:  JournalSubmit,
+			Asset:   AssetCrypto,
+			URL:     EPCryptoOrders,
+			RefID:   o.RefID,
+			Request: payload,
+		}, out, err)
+	}()
+
+	if c.DryRun {
+		c.logDryRun("POST", EPCryptoOrders, payload)
+		return dryRunCryptoOrder(o), nil
+	}
+
 	post, err := http.NewRequest("POST", EPCryptoOrders, bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("could not create Crypto http.Request: %w", err)
@@ -98,13 +108,22 @@ func (c *Client) SubmitCryptoOrder(ctx context.Context, o *CryptoOrder) (*Crypto
 
 	post.Header.Add("Content-Type", "application/json")
 
-	var out CryptoOrderOutput
-	err = c.DoAndDecode(ctx, post, &out)
-	return &out, err
+	out = &CryptoOrderOutput{}
+	err = c.DoAndDecode(ctx, post, out)
+	return out, err
 }
 
 // Cancel will cancel the order.
-func (o *CryptoOrderOutput) Cancel(ctx context.Context, c *Client) error {
+func (o *CryptoOrderOutput) Cancel(ctx context.Context, c *Client) (err error) {
+	defer func() {
+		c.journalCancel(ctx, AssetCrypto, o.ID, o.CancelURL, err)
+	}()
+
+	if c.DryRun {
+		c.logDryRun("POST", o.CancelURL, nil)
+		return nil
+	}
+
 	post, err := http.NewRequest("POST", o.CancelURL, nil)
 	if err != nil {
 		return err
@@ -124,9 +143,17 @@ func (o *CryptoOrderOutput) Cancel(ctx context.Context, c *Client) error {
 	return nil
 }
 
-func (c *Client) CancelCryptoOrderById(ctx context.Context, id string) error {
+func (c *Client) CancelCryptoOrderById(ctx context.Context, id string) (err error) {
 
 	var ordUrl = EPCryptoOrders + id + "/cancel/"
+	defer func() {
+		c.journalCancel(ctx, AssetCrypto, id, ordUrl, err)
+	}()
+
+	if c.DryRun {
+		c.logDryRun("POST", ordUrl, nil)
+		return nil
+	}
 
 	post, err := http.NewRequest("POST", ordUrl, nil)
 	if err != nil {
@@ -176,7 +203,11 @@ func (c *Client) GetCryptoOrders(ctx context.Context, nextUrl *string, pgSize in
 }
 
 // Update returns any errors and updates the item with any recent changes.
+// Synthetic dry-run orders have nothing to fetch and are left unchanged.
 func (o *CryptoOrderOutput) Update(ctx context.Context, c *Client) error {
+	if o.State == OrderStateDryRun {
+		return nil
+	}
 	ordUrl := EPCryptoOrders + fmt.Sprintf("%s", o.ID)
 	return c.GetAndDecode(ctx, ordUrl, o)
 }
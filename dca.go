@@ -0,0 +1,387 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// A Cadence is how often a RecurringInvestment buys.
+type Cadence string
+
+// Supported cadences.
+const (
+	Daily    Cadence = "daily"
+	Weekly   Cadence = "weekly"
+	Biweekly Cadence = "biweekly"
+	Monthly  Cadence = "monthly"
+)
+
+func (c Cadence) valid() bool {
+	switch c {
+	case Daily, Weekly, Biweekly, Monthly:
+		return true
+	}
+	return false
+}
+
+// after returns the first occurrence of the cadence, counting from t, that
+// is later than now. Monthly occurrences fall on the given day of the month,
+// or the month's last day if it is shorter; a day of zero means t's.
+func (c Cadence) after(t, now time.Time, day int) (time.Time, error) {
+	if day == 0 {
+		day = t.Day()
+	}
+	for !t.After(now) {
+		switch c {
+		case Daily:
+			t = t.AddDate(0, 0, 1)
+		case Weekly:
+			t = t.AddDate(0, 0, 7)
+		case Biweekly:
+			t = t.AddDate(0, 0, 14)
+		case Monthly:
+			// AddDate would overflow into the next month and then stay
+			// on the overflowed day, e.g. Jan 31 to Mar 2 and then the 2nd.
+			y, m, _ := t.Date()
+			last := time.Date(y, m+2, 0, 0, 0, 0, 0, t.Location()).Day()
+			d := day
+			if d > last {
+				d = last
+			}
+			t = time.Date(y, m+1, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		default:
+			return t, fmt.Errorf("unknown cadence %q", c)
+		}
+	}
+	return t, nil
+}
+
+// A RecurringInvestment buys a fixed dollar amount of a stock or crypto
+// currency on a regular cadence. Exactly one of Symbol and CryptoSymbol is
+// set.
+type RecurringInvestment struct {
+	ID string `json:"id"`
+	// Symbol is the ticker of a fractionally tradable stock.
+	Symbol string `json:"symbol,omitempty"`
+	// CryptoSymbol is the code of a crypto currency, e.g. "BTC".
+	CryptoSymbol string  `json:"crypto_symbol,omitempty"`
+	Amount       float64 `json:"amount"`
+	Cadence      Cadence `json:"cadence"`
+	// NextRun is when the investment next comes due.
+	NextRun time.Time `json:"next_run"`
+	// Day is the day of the month a monthly investment comes due, taken
+	// from the first NextRun.
+	Day    int  `json:"day,omitempty"`
+	Paused bool `json:"paused,omitempty"`
+}
+
+// An InvestmentRun records one occurrence of a RecurringInvestment.
+type InvestmentRun struct {
+	InvestmentID string    `json:"investment_id"`
+	Symbol       string    `json:"symbol"`
+	Scheduled    time.Time `json:"scheduled"`
+	At           time.Time `json:"at"`
+	Amount       float64   `json:"amount"`
+	Price        float64   `json:"price,omitempty"`
+	Quantity     float64   `json:"quantity,omitempty"`
+	OrderID      string    `json:"order_id,omitempty"`
+	State        string    `json:"state,omitempty"`
+	// Skipped explains why no order was placed, e.g. a market holiday.
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+}
+
+// A DCAScheduler places dollar-cost averaging buys for a set of
+// RecurringInvestments. Equity buys due on a day the market is closed are
+// skipped rather than postponed. If the scheduler is not run for a while, a
+// late investment buys once and then resumes its cadence.
+//
+// If Path is set, investments and the record of every run are saved there
+// after each change.
+type DCAScheduler struct {
+	Client *Client
+	Path   string
+	// DryRun records the orders that would be placed without placing
+	// them, independently of Client.DryRun.
+	DryRun bool
+
+	mu    sync.Mutex
+	state struct {
+		Investments []*RecurringInvestment `json:"investments"`
+		Runs        []InvestmentRun        `json:"runs"`
+	}
+}
+
+// NewDCAScheduler returns a scheduler for the client, restoring any state
+// previously saved at path. An empty path keeps state in memory only.
+func NewDCAScheduler(c *Client, path string) (*DCAScheduler, error) {
+	s := &DCAScheduler{Client: c, Path: path}
+	if path == "" {
+		return s, nil
+	}
+	if err := loadJSON(path, &s.state); err != nil {
+		return nil, errors.Wrap(err, "could not load recurring investments")
+	}
+	return s, nil
+}
+
+// Add registers a new recurring investment. If NextRun is zero, it first
+// comes due immediately.
+func (s *DCAScheduler) Add(inv RecurringInvestment) (*RecurringInvestment, error) {
+	if (inv.Symbol == "") == (inv.CryptoSymbol == "") {
+		return nil, errors.New("exactly one of Symbol and CryptoSymbol must be set")
+	}
+	if inv.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if !inv.Cadence.valid() {
+		return nil, fmt.Errorf("unknown cadence %q", inv.Cadence)
+	}
+
+	inv.ID = uuid.New().String()
+	if inv.NextRun.IsZero() {
+		inv.NextRun = time.Now()
+	}
+	if inv.Cadence == Monthly && inv.Day == 0 {
+		inv.Day = inv.NextRun.Day()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Investments = append(s.state.Investments, &inv)
+	if err := s.save(); err != nil {
+		s.state.Investments = s.state.Investments[:len(s.state.Investments)-1]
+		return nil, err
+	}
+
+	out := inv
+	return &out, nil
+}
+
+// Remove deletes a recurring investment. Its past runs are kept.
+func (s *DCAScheduler) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, inv := range s.state.Investments {
+		if inv.ID == id {
+			s.state.Investments = append(s.state.Investments[:i:i], s.state.Investments[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("no recurring investment %q", id)
+}
+
+// SetPaused pauses or resumes a recurring investment. A paused investment
+// never comes due.
+func (s *DCAScheduler) SetPaused(id string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range s.state.Investments {
+		if inv.ID == id {
+			inv.Paused = paused
+			return s.save()
+		}
+	}
+	return fmt.Errorf("no recurring investment %q", id)
+}
+
+// Investments returns the registered recurring investments.
+func (s *DCAScheduler) Investments() []RecurringInvestment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]RecurringInvestment, len(s.state.Investments))
+	for i, inv := range s.state.Investments {
+		out[i] = *inv
+	}
+	return out
+}
+
+// Runs returns the record of every run so far, oldest first.
+func (s *DCAScheduler) Runs() []InvestmentRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]InvestmentRun(nil), s.state.Runs...)
+}
+
+// Run calls RunDue every interval until the context is cancelled.
+func (s *DCAScheduler) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		if _, err := s.RunDue(ctx, time.Now()); err != nil {
+			s.Client.logf("robinhood: recurring investments: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// RunDue places the buys for every investment due at now and returns a record
+// of each. Failures to place individual orders are recorded in their runs;
+// the returned error reports only a failure to save state.
+func (s *DCAScheduler) RunDue(ctx context.Context, now time.Time) ([]InvestmentRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []InvestmentRun
+	for _, inv := range s.state.Investments {
+		if inv.Paused || inv.NextRun.After(now) {
+			continue
+		}
+
+		run := s.invest(ctx, inv)
+		run.Scheduled = inv.NextRun
+		runs = append(runs, run)
+
+		next, err := inv.Cadence.after(inv.NextRun, now, inv.Day)
+		if err != nil {
+			return runs, err
+		}
+		inv.NextRun = next
+	}
+
+	s.state.Runs = append(s.state.Runs, runs...)
+	return runs, s.save()
+}
+
+func (s *DCAScheduler) invest(ctx context.Context, inv *RecurringInvestment) InvestmentRun {
+	run := InvestmentRun{
+		InvestmentID: inv.ID,
+		Symbol:       inv.Symbol,
+		At:           time.Now(),
+		Amount:       inv.Amount,
+		DryRun:       s.DryRun || s.Client.DryRun,
+	}
+
+	var err error
+	if inv.CryptoSymbol != "" {
+		run.Symbol = inv.CryptoSymbol
+		err = s.investCrypto(ctx, inv, &run)
+	} else {
+		err = s.investEquity(ctx, inv, &run)
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
+}
+
+func (s *DCAScheduler) investEquity(ctx context.Context, inv *RecurringInvestment, run *InvestmentRun) error {
+	day := inv.NextRun.In(nyLoc())
+	h, err := s.Client.GetMarketHours(ctx, MarketNYSE, NewZonedDate(day.Year(), int(day.Month()), day.Day(), nyLoc()))
+	if err != nil {
+		return errors.Wrap(err, "could not get market hours")
+	}
+	if !h.IsOpen {
+		run.Skipped = "market closed on " + h.Date.String()
+		return nil
+	}
+
+	inst, err := s.Client.GetInstrumentForSymbol(ctx, inv.Symbol)
+	if err != nil {
+		return errors.Wrap(err, "could not look up instrument")
+	}
+
+	qs, err := s.Client.GetQuote(ctx, inv.Symbol)
+	if err != nil {
+		return errors.Wrap(err, "could not get quote")
+	}
+	if len(qs) < 1 {
+		return fmt.Errorf("no quote for %s", inv.Symbol)
+	}
+	price := qs[0].AskPrice
+	if price == 0 {
+		price = qs[0].Price()
+	}
+
+	ord := s.Client.CreateDollarOrder(inst, inv.Amount, price)
+	run.Price = ord.Price
+	run.Quantity = ord.Quantity
+
+	if s.DryRun {
+		run.State = OrderStateDryRun
+		return nil
+	}
+
+	out, err := s.Client.SubmitOrder(ctx, ord)
+	if err != nil {
+		return errors.Wrap(err, "could not submit order")
+	}
+	run.OrderID = out.ID
+	run.State = out.State
+	return nil
+}
+
+func (s *DCAScheduler) investCrypto(ctx context.Context, inv *RecurringInvestment, run *InvestmentRun) error {
+	pair, err := s.Client.GetCryptoInstrument(ctx, strings.ToUpper(inv.CryptoSymbol))
+	if err != nil {
+		return errors.Wrap(err, "could not look up currency pair")
+	}
+
+	q, err := s.Client.GetCryptoQuote(ctx, pair.ID)
+	if err != nil {
+		return errors.Wrap(err, "could not get quote")
+	}
+	price := q.AskPrice
+	if price == 0 {
+		price = q.MarkPrice
+	}
+	if price == 0 {
+		return fmt.Errorf("no price for %s", inv.CryptoSymbol)
+	}
+
+	inc := pair.CyrptoAssetCurrency.Increment
+	if inc == 0 {
+		inc = 1e-8
+	}
+
+	ord := s.Client.CreateCryptoOrder(pair.ID)
+	ord.Side = strings.ToLower(Buy.String())
+	ord.RefID = uuid.New().String()
+	ord.Price = price
+	ord.AmountInDollars = inv.Amount
+	ord.Quantity = math.Round(math.Floor(inv.Amount/price/inc)*inc*1e8) / 1e8
+	if ord.Quantity <= 0 {
+		return fmt.Errorf("$%.2f is below the minimum increment of %s", inv.Amount, inv.CryptoSymbol)
+	}
+	run.Price = ord.Price
+	run.Quantity = ord.Quantity
+
+	if s.DryRun {
+		run.State = OrderStateDryRun
+		return nil
+	}
+
+	out, err := s.Client.SubmitCryptoOrder(ctx, ord)
+	if err != nil {
+		return errors.Wrap(err, "could not submit crypto order")
+	}
+	run.OrderID = out.ID
+	run.State = out.State
+	return nil
+}
+
+func (s *DCAScheduler) save() error {
+	if s.Path == "" {
+		return nil
+	}
+	if err := saveJSON(s.Path, s.state); err != nil {
+		return errors.Wrap(err, "could not save recurring investments")
+	}
+	return nil
+}
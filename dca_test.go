@@ -0,0 +1,50 @@
+package robinhood
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCadenceAfter(t *testing.T) {
+	asrt := assert.New(t)
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	// Monthly runs keep to the 31st, using the last day of shorter months.
+	next := day(2023, time.December, 31)
+	var got []time.Time
+	for i := 0; i < 5; i++ {
+		var err error
+		next, err = Monthly.after(next, next, 31)
+		asrt.NoError(err)
+		got = append(got, next)
+	}
+	asrt.Equal([]time.Time{
+		day(2024, time.January, 31),
+		day(2024, time.February, 29),
+		day(2024, time.March, 31),
+		day(2024, time.April, 30),
+		day(2024, time.May, 31),
+	}, got)
+
+	// Missed runs are skipped rather than made up.
+	next, err := Monthly.after(day(2024, time.January, 31), day(2024, time.June, 15), 31)
+	asrt.NoError(err)
+	asrt.Equal(day(2024, time.June, 30), next)
+
+	// A day of zero uses the day of t.
+	next, err = Monthly.after(day(2024, time.January, 15), day(2024, time.January, 15), 0)
+	asrt.NoError(err)
+	asrt.Equal(day(2024, time.February, 15), next)
+
+	next, err = Weekly.after(day(2024, time.January, 1), day(2024, time.January, 10), 0)
+	asrt.NoError(err)
+	asrt.Equal(day(2024, time.January, 15), next)
+
+	_, err = Cadence("hourly").after(day(2024, time.January, 1), day(2024, time.January, 1), 0)
+	asrt.Error(err)
+}
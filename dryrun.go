@@ -0,0 +1,105 @@
+package robinhood
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderStateDryRun is the State of the synthetic orders returned while a
+// Client is in DryRun mode.
+const OrderStateDryRun = "dry_run"
+
+// logf writes to the client's Logger, or the standard logger if none is set.
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// logDryRun records a request that would have been sent had DryRun not been
+// set.
+func (c *Client) logDryRun(method, url string, payload []byte) {
+	if len(payload) == 0 {
+		c.logf("robinhood: dry run: %s %s", method, url)
+		return
+	}
+	c.logf("robinhood: dry run: %s %s %s", method, url, payload)
+}
+
+// dryRunOrder builds the OrderOutput returned in place of a submitted order.
+func dryRunOrder(o *RhOrder) *OrderOutput {
+	now := time.Now()
+	return &OrderOutput{
+		ID:                     uuid.New().String(),
+		RefID:                  o.RefID,
+		Account:                o.Account,
+		Instrument:             o.Instrument,
+		State:                  OrderStateDryRun,
+		Type:                   o.Type,
+		Side:                   o.Side,
+		TimeInForce:            o.TimeInForce,
+		Trigger:                o.Trigger,
+		Price:                  o.Price,
+		StopPrice:              o.StopPrice,
+		Quantity:               o.Quantity,
+		ExtendedHours:          o.ExtendedHours,
+		OverrideDtbpChecks:     o.OverrideDtbpChecks,
+		OverrideDayTradeChecks: o.OverrideDayTradeChecks,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+}
+
+// dryRunCryptoOrder builds the CryptoOrderOutput returned in place of a
+// submitted crypto order.
+func dryRunCryptoOrder(o *CryptoOrder) *CryptoOrderOutput {
+	now := time.Now()
+	return &CryptoOrderOutput{
+		ID:             uuid.New().String(),
+		RefID:          o.RefID,
+		AccountID:      o.AccountID,
+		CurrencyPairID: o.CurrencyPairID,
+		EnteredPrice:   o.Price,
+		Price:          o.Price,
+		Quantity:       o.Quantity,
+		Side:           o.Side,
+		State:          OrderStateDryRun,
+		TimeInForce:    o.TimeInForce,
+		Type:           o.Type,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// dryRunOptionOrder builds the OptionOrderOutput returned in place of a
+// submitted options order.
+func dryRunOptionOrder(in optionInput) *OptionOrderOutput {
+	now := time.Now()
+	out := &OptionOrderOutput{
+		ID:          uuid.New().String(),
+		RefID:       in.RefID,
+		State:       OrderStateDryRun,
+		Type:        strings.ToLower(in.Type.String()),
+		TimeInForce: strings.ToLower(in.TimeInForce.String()),
+		Trigger:     in.Trigger,
+		Direction:   strings.ToLower(in.Direction.String()),
+		Price:       in.Price,
+		Quantity:    in.Quantity,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, l := range in.Legs {
+		out.Legs = append(out.Legs, OptionOrderLeg{
+			Option:         l.Option,
+			PositionEffect: l.PositionEffect,
+			RatioQuantity:  l.RatioQuantity,
+			Side:           strings.ToLower(l.Side.String()),
+		})
+	}
+	return out
+}
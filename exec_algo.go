@@ -0,0 +1,359 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Execution algorithms supported by StartExecution.
+const (
+	// AlgoTWAP spreads the parent quantity evenly over the window.
+	AlgoTWAP = "twap"
+	// AlgoVWAP spreads the parent quantity in proportion to the stock's
+	// typical intraday volume over the window.
+	AlgoVWAP = "vwap"
+)
+
+// ExecParams describes a parent order to be worked by an ExecEngine.
+type ExecParams struct {
+	Instrument *Instrument
+	Side       OrderSide
+	Quantity   float64
+	Algo       string
+	// Start and End bound the execution window. A zero Start means now.
+	Start, End time.Time
+	// Slices is the number of child orders. Defaults to 10.
+	Slices int
+	// LimitOffset is how far beyond the quote child limits are placed, as a
+	// fraction of the price: buys at ask*(1+LimitOffset) and sells at
+	// bid*(1-LimitOffset). Defaults to 0.001.
+	LimitOffset float64
+	// Fractional allows fractional child quantities. Otherwise children are
+	// rounded down to whole shares.
+	Fractional bool
+}
+
+// ExecProgress is a snapshot of the work done by an ExecEngine.
+type ExecProgress struct {
+	Quantity     float64
+	Filled       float64
+	Remaining    float64
+	AveragePrice float64
+	Slices       int
+	SlicesDone   int
+	Children     []OrderOutput
+	Done         bool
+	Err          error
+}
+
+// An ExecEngine works a parent order by submitting child orders over time.
+// It is created by StartExecution.
+type ExecEngine struct {
+	c       *Client
+	p       ExecParams
+	weights []float64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	progress ExecProgress
+	notional float64
+}
+
+// StartExecution begins working the parent order described by p in the
+// background. Each child is a marketable limit order priced off the latest
+// Quote that is cancelled if still working when its slice ends; anything it
+// leaves unfilled is spread over the remaining slices.
+func (c *Client) StartExecution(ctx context.Context, p ExecParams) (*ExecEngine, error) {
+	if p.Instrument == nil || p.Quantity <= 0 {
+		return nil, errors.New("an instrument and a positive quantity are required")
+	}
+	if p.Start.IsZero() {
+		p.Start = time.Now()
+	}
+	if !p.End.After(p.Start) {
+		return nil, errors.New("execution window must end after it starts")
+	}
+	if p.Slices <= 0 {
+		p.Slices = 10
+	}
+	if p.LimitOffset == 0 {
+		p.LimitOffset = 0.001
+	}
+
+	var weights []float64
+	switch p.Algo {
+	case AlgoTWAP:
+		weights = evenWeights(p.Slices)
+	case AlgoVWAP:
+		var err error
+		weights, err = c.volumeWeights(ctx, p)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not compute volume profile")
+		}
+	default:
+		return nil, fmt.Errorf("unknown execution algorithm %q", p.Algo)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e := &ExecEngine{
+		c:       c,
+		p:       p,
+		weights: weights,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		progress: ExecProgress{
+			Quantity:  p.Quantity,
+			Remaining: p.Quantity,
+			Slices:    p.Slices,
+		},
+	}
+
+	go e.run(ctx)
+	return e, nil
+}
+
+// Progress returns a snapshot of the engine's work so far.
+func (e *ExecEngine) Progress() ExecProgress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	p := e.progress
+	p.Children = append([]OrderOutput(nil), e.progress.Children...)
+	return p
+}
+
+// Done returns a channel that is closed when the engine finishes.
+func (e *ExecEngine) Done() <-chan struct{} {
+	return e.done
+}
+
+// Stop halts the engine, cancelling any working child order, and waits for
+// it to finish.
+func (e *ExecEngine) Stop() {
+	e.cancel()
+	<-e.done
+}
+
+func (e *ExecEngine) run(ctx context.Context) {
+	defer close(e.done)
+
+	err := e.work(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.progress.Done = true
+	if err != nil && err != context.Canceled {
+		e.progress.Err = err
+	}
+}
+
+func (e *ExecEngine) work(ctx context.Context) error {
+	n := e.p.Slices
+	step := e.p.End.Sub(e.p.Start) / time.Duration(n)
+
+	for i := 0; i < n; i++ {
+		start := e.p.Start.Add(time.Duration(i) * step)
+		if err := sleepUntil(ctx, start); err != nil {
+			return err
+		}
+
+		remaining := e.Progress().Remaining
+		if remaining <= 0 {
+			return nil
+		}
+
+		// Spread what is left over the weight of the slices still to come.
+		var rest float64
+		for _, w := range e.weights[i:] {
+			rest += w
+		}
+		qty := remaining
+		if rest > 0 {
+			qty = remaining * e.weights[i] / rest
+		}
+		qty = e.roundQuantity(math.Min(qty, remaining))
+
+		if qty > 0 {
+			if err := e.slice(ctx, qty, start.Add(step)); err != nil {
+				return err
+			}
+		}
+
+		e.mu.Lock()
+		e.progress.SlicesDone++
+		e.mu.Unlock()
+	}
+	return nil
+}
+
+// slice submits one child order and works it until end.
+func (e *ExecEngine) slice(ctx context.Context, qty float64, end time.Time) error {
+	price, err := e.limitPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	ord := e.c.CreateOrder(e.p.Instrument)
+	ord.Side = strings.ToLower(e.p.Side.String())
+	ord.Type = strings.ToLower(Limit.String())
+	ord.Price = price
+	ord.Quantity = qty
+
+	out, err := e.c.SubmitOrder(ctx, ord)
+	if err != nil {
+		return errors.Wrap(err, "could not submit child order")
+	}
+
+	e.mu.Lock()
+	e.progress.Children = append(e.progress.Children, *out)
+	idx := len(e.progress.Children) - 1
+	e.mu.Unlock()
+
+	poll := time.Until(end) / 4
+	if poll > 5*time.Second {
+		poll = 5 * time.Second
+	}
+	if poll < time.Second {
+		poll = time.Second
+	}
+
+	wctx, cancel := context.WithDeadline(ctx, end)
+	err = out.Wait(wctx, e.c, poll)
+	cancel()
+
+	if !out.IsDone() {
+		// Cancel with a fresh context, since ctx may be why we are here.
+		cctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if cerr := out.Cancel(cctx, e.c); cerr != nil {
+			e.c.logf("robinhood: could not cancel child order %s: %v", out.ID, cerr)
+		}
+		if werr := out.Wait(cctx, e.c, time.Second); werr != nil {
+			e.c.logf("robinhood: could not confirm cancellation of %s: %v", out.ID, werr)
+		}
+	}
+
+	// A dry-run child is taken to fill in full at its limit, so that the
+	// slices that follow are sized as they would be for real.
+	filled, avg := out.CumulativeQuantity, out.AveragePrice
+	if out.State == OrderStateDryRun {
+		filled, avg = out.Quantity, out.Price
+	}
+
+	e.mu.Lock()
+	e.progress.Children[idx] = *out
+	e.progress.Filled += filled
+	e.progress.Remaining = e.progress.Quantity - e.progress.Filled
+	e.notional += filled * avg
+	if e.progress.Filled > 0 {
+		e.progress.AveragePrice = e.notional / e.progress.Filled
+	}
+	e.mu.Unlock()
+
+	if err != nil && err != context.DeadlineExceeded {
+		return err
+	}
+	return ctx.Err()
+}
+
+// limitPrice returns a marketable limit price from the latest quote.
+func (e *ExecEngine) limitPrice(ctx context.Context) (float64, error) {
+	qs, err := e.c.GetQuote(ctx, e.p.Instrument.Symbol)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get quote")
+	}
+	if len(qs) < 1 {
+		return 0, fmt.Errorf("no quote for %s", e.p.Instrument.Symbol)
+	}
+	q := qs[0]
+
+	if e.p.Side == Buy {
+		p := q.AskPrice
+		if p == 0 {
+			p = q.Price()
+		}
+		return math.Ceil(p*(1+e.p.LimitOffset)*100) / 100, nil
+	}
+
+	p := q.BidPrice
+	if p == 0 {
+		p = q.Price()
+	}
+	return math.Floor(p*(1-e.p.LimitOffset)*100) / 100, nil
+}
+
+func (e *ExecEngine) roundQuantity(q float64) float64 {
+	if e.p.Fractional {
+		return math.Floor(q*1e6) / 1e6
+	}
+	return math.Floor(q)
+}
+
+// volumeWeights returns the share of typical volume traded during each slice
+// of the window, based on the past week of 5 minute bars.
+func (c *Client) volumeWeights(ctx context.Context, p ExecParams) ([]float64, error) {
+	hs, err := c.GetQuoteHistoricals(ctx, Interval5Minute, SpanWeek, BoundsRegular, p.Instrument.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Total volume by minute of the New York trading day.
+	byMinute := map[int]float64{}
+	for _, h := range hs {
+		for _, b := range h.Bars {
+			byMinute[MinuteOfDay(b.BeginsAt.In(nyLoc()))] += float64(b.Volume)
+		}
+	}
+
+	ws := make([]float64, p.Slices)
+	step := p.End.Sub(p.Start) / time.Duration(p.Slices)
+	var total float64
+	for i := range ws {
+		from := MinuteOfDay(p.Start.Add(time.Duration(i) * step).In(nyLoc()))
+		to := MinuteOfDay(p.Start.Add(time.Duration(i+1) * step).In(nyLoc()))
+		for m, v := range byMinute {
+			if from <= m && m < to {
+				ws[i] += v
+			}
+		}
+		total += ws[i]
+	}
+
+	if total == 0 {
+		return evenWeights(p.Slices), nil
+	}
+	return ws, nil
+}
+
+func evenWeights(n int) []float64 {
+	ws := make([]float64, n)
+	for i := range ws {
+		ws[i] = 1
+	}
+	return ws
+}
+
+// sleepUntil blocks until t or until the context is cancelled.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	tm := time.NewTimer(d)
+	defer tm.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-tm.C:
+		return nil
+	}
+}
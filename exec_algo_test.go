@@ -0,0 +1,48 @@
+package robinhood
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionDryRun(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	f.setPrice(50)
+	c := newTestClient(t, f)
+	c.Account = &Account{}
+	c.DryRun = true
+	c.Logger = log.New(ioutil.Discard, "", 0)
+
+	// A window already past runs every slice at once.
+	now := time.Now()
+	e, err := c.StartExecution(context.Background(), ExecParams{
+		Instrument: &Instrument{Symbol: "ABC"},
+		Side:       Buy,
+		Quantity:   100,
+		Algo:       AlgoTWAP,
+		Start:      now.Add(-time.Hour),
+		End:        now.Add(-time.Minute),
+	})
+	asrt.NoError(err)
+	<-e.Done()
+
+	p := e.Progress()
+	asrt.NoError(p.Err)
+	var total float64
+	for _, ch := range p.Children {
+		total += ch.Quantity
+	}
+	asrt.Len(p.Children, 10)
+	asrt.InDelta(100, total, 1e-9)
+	asrt.InDelta(100, p.Filled, 1e-9)
+	asrt.InDelta(0, p.Remaining, 1e-9)
+	asrt.InDelta(50.05, p.AveragePrice, 1e-9)
+	asrt.Empty(f.posted)
+}
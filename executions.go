@@ -0,0 +1,136 @@
+package robinhood
+
+import (
+	"sort"
+	"time"
+)
+
+// An Execution is a single fill of an equity order.
+type Execution struct {
+	Price                  float64   `json:"price,string"`
+	Quantity               float64   `json:"quantity,string"`
+	SettlementDate         string    `json:"settlement_date"`
+	Timestamp              time.Time `json:"timestamp"`
+	ID                     string    `json:"id"`
+	IpoAccessExecutionRank float64   `json:"ipo_access_execution_rank"`
+}
+
+// Fill returns the execution in the form shared with crypto executions.
+func (e Execution) Fill() Fill {
+	return Fill{
+		ID:             e.ID,
+		Price:          e.Price,
+		Quantity:       e.Quantity,
+		Timestamp:      e.Timestamp,
+		SettlementDate: e.SettlementDate,
+	}
+}
+
+// A CryptoExecution is a single fill of a crypto order.
+type CryptoExecution struct {
+	EffectivePrice float64   `json:"effective_price,string"`
+	ID             string    `json:"id"`
+	Quantity       float64   `json:"quantity,string"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Fill returns the execution in the form shared with equity executions.
+func (e CryptoExecution) Fill() Fill {
+	return Fill{
+		ID:        e.ID,
+		Price:     e.EffectivePrice,
+		Quantity:  e.Quantity,
+		Timestamp: e.Timestamp,
+	}
+}
+
+// A Fill is an execution of either an equity or a crypto order.
+type Fill struct {
+	ID        string
+	Price     float64
+	Quantity  float64
+	Timestamp time.Time
+	// SettlementDate is empty for crypto fills, which settle immediately.
+	SettlementDate string
+}
+
+// FillSummary aggregates the executions of an order.
+type FillSummary struct {
+	Executions int
+	Ordered    float64
+	Filled     float64
+	Remaining  float64
+	// Notional is the total value of the fills, before fees.
+	Notional float64
+	// AveragePrice is the volume-weighted average fill price.
+	AveragePrice float64
+	Fees         float64
+	First, Last  time.Time
+	// SettlementDates lists the distinct settlement dates, earliest first.
+	SettlementDates []string
+}
+
+// SummarizeFills aggregates fills against the quantity ordered. Fees are
+// reported at the order level by the API and so are passed in separately.
+func SummarizeFills(fills []Fill, ordered, fees float64) FillSummary {
+	s := FillSummary{
+		Executions: len(fills),
+		Ordered:    ordered,
+		Fees:       fees,
+	}
+
+	dates := map[string]bool{}
+	for _, f := range fills {
+		s.Filled += f.Quantity
+		s.Notional += f.Price * f.Quantity
+
+		if s.First.IsZero() || f.Timestamp.Before(s.First) {
+			s.First = f.Timestamp
+		}
+		if f.Timestamp.After(s.Last) {
+			s.Last = f.Timestamp
+		}
+
+		if f.SettlementDate != "" && !dates[f.SettlementDate] {
+			dates[f.SettlementDate] = true
+			s.SettlementDates = append(s.SettlementDates, f.SettlementDate)
+		}
+	}
+	sort.Strings(s.SettlementDates)
+
+	if s.Filled > 0 {
+		s.AveragePrice = s.Notional / s.Filled
+	}
+	if ordered > s.Filled {
+		s.Remaining = ordered - s.Filled
+	}
+	return s
+}
+
+// Fills returns the order's executions.
+func (o OrderOutput) Fills() []Fill {
+	fs := make([]Fill, len(o.Executions))
+	for i, e := range o.Executions {
+		fs[i] = e.Fill()
+	}
+	return fs
+}
+
+// FillSummary aggregates the order's executions.
+func (o OrderOutput) FillSummary() FillSummary {
+	return SummarizeFills(o.Fills(), o.Quantity, o.Fees)
+}
+
+// Fills returns the order's executions.
+func (o CryptoOrderOutput) Fills() []Fill {
+	fs := make([]Fill, len(o.Executions))
+	for i, e := range o.Executions {
+		fs[i] = e.Fill()
+	}
+	return fs
+}
+
+// FillSummary aggregates the order's executions.
+func (o CryptoOrderOutput) FillSummary() FillSummary {
+	return SummarizeFills(o.Fills(), o.Quantity, 0)
+}
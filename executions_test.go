@@ -0,0 +1,52 @@
+package robinhood
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderFillSummary(t *testing.T) {
+	asrt := assert.New(t)
+
+	var o OrderOutput
+	err := json.Unmarshal([]byte(`{
+		"quantity": "10.00000000",
+		"fees": "0.02",
+		"executions": [
+			{"price": "100.00", "quantity": "4.00000", "settlement_date": "2024-01-23", "timestamp": "2024-01-19T14:30:01Z", "id": "a"},
+			{"price": "101.00", "quantity": "2.00000", "settlement_date": "2024-01-22", "timestamp": "2024-01-19T14:30:00Z", "id": "b"}
+		]
+	}`), &o)
+	asrt.NoError(err)
+
+	s := o.FillSummary()
+	asrt.Equal(2, s.Executions)
+	asrt.InDelta(6, s.Filled, 1e-9)
+	asrt.InDelta(4, s.Remaining, 1e-9)
+	asrt.InDelta(602, s.Notional, 1e-9)
+	asrt.InDelta(602.0/6, s.AveragePrice, 1e-9)
+	asrt.InDelta(0.02, s.Fees, 1e-9)
+	asrt.Equal("b", o.Executions[1].ID)
+	asrt.Equal([]string{"2024-01-22", "2024-01-23"}, s.SettlementDates)
+	asrt.True(s.First.Before(s.Last))
+}
+
+func TestCryptoFillSummary(t *testing.T) {
+	asrt := assert.New(t)
+
+	o := CryptoOrderOutput{
+		Quantity: 1,
+		Executions: []CryptoExecution{
+			{EffectivePrice: 40000, Quantity: 0.25},
+			{EffectivePrice: 42000, Quantity: 0.75},
+		},
+	}
+
+	s := o.FillSummary()
+	asrt.InDelta(1, s.Filled, 1e-9)
+	asrt.Zero(s.Remaining)
+	asrt.InDelta(41500, s.AveragePrice, 1e-9)
+	asrt.Empty(s.SettlementDates)
+}
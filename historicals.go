@@ -0,0 +1,112 @@
+package robinhood
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Intervals, spans and bounds accepted by the historicals endpoints.
+const (
+	Interval5Minute  = "5minute"
+	Interval10Minute = "10minute"
+	IntervalHour     = "hour"
+	IntervalDay      = "day"
+	IntervalWeek     = "week"
+
+	SpanDay    = "day"
+	SpanWeek   = "week"
+	SpanMonth  = "month"
+	Span3Month = "3month"
+	SpanYear   = "year"
+	Span5Year  = "5year"
+
+	BoundsRegular  = "regular"
+	BoundsExtended = "extended"
+	BoundsTrading  = "trading"
+)
+
+// A Bar is one interval of historical pricing data.
+type Bar struct {
+	BeginsAt     time.Time `json:"begins_at"`
+	OpenPrice    float64   `json:"open_price,string"`
+	ClosePrice   float64   `json:"close_price,string"`
+	HighPrice    float64   `json:"high_price,string"`
+	LowPrice     float64   `json:"low_price,string"`
+	Volume       int64     `json:"volume"`
+	Session      string    `json:"session"`
+	Interpolated bool      `json:"interpolated"`
+}
+
+// QuoteHistoricals holds the historical bars for a single stock.
+type QuoteHistoricals struct {
+	Symbol     string `json:"symbol"`
+	Instrument string `json:"instrument"`
+	Interval   string `json:"interval"`
+	Span       string `json:"span"`
+	Bounds     string `json:"bounds"`
+	Bars       []Bar  `json:"historicals"`
+}
+
+// GetQuoteHistoricals returns historical bars at the given interval, covering
+// the given span, for each of the listed stocks. Bounds may be left empty
+// for regular trading hours only.
+func (c *Client) GetQuoteHistoricals(ctx context.Context, interval, span, bounds string, symbols ...string) ([]QuoteHistoricals, error) {
+	v := url.Values{
+		"symbols":  []string{strings.Join(symbols, ",")},
+		"interval": []string{interval},
+		"span":     []string{span},
+	}
+	if bounds != "" {
+		v.Set("bounds", bounds)
+	}
+
+	var r struct{ Results []QuoteHistoricals }
+	err := c.GetAndDecode(ctx, EPQuotes+"historicals/?"+v.Encode(), &r)
+	return r.Results, err
+}
+
+// OptionHistoricals holds the historical bars for a single option. Prices are
+// per share, like the option's mark.
+type OptionHistoricals struct {
+	Instrument *OptionInstrument `json:"-"`
+	Interval   string            `json:"interval"`
+	Span       string            `json:"span"`
+	Bounds     string            `json:"bounds"`
+	Bars       []Bar             `json:"data_points"`
+}
+
+// GetOptionHistoricals returns historical bars at the given interval,
+// covering the given span, for each of the listed options, in the order they
+// are listed. Options are requested a few at a time.
+func (c *Client) GetOptionHistoricals(ctx context.Context, interval, span string, opts ...*OptionInstrument) ([]OptionHistoricals, error) {
+	v := url.Values{
+		"interval": []string{interval},
+		"span":     []string{span},
+	}
+
+	out := make([]OptionHistoricals, len(opts))
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(marketDataWorkers)
+	for i, o := range opts {
+		i, o := i, o
+		eg.Go(func() error {
+			err := c.GetAndDecode(ctx, EPOptionQuote+"historicals/"+o.ID+"/?"+v.Encode(), &out[i])
+			if err != nil {
+				return errors.Wrapf(err, "could not get historicals for option %s", o.ID)
+			}
+			out[i].Instrument = o
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
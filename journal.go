@@ -0,0 +1,188 @@
+package robinhood
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Actions recorded in a Journal.
+const (
+	JournalSubmit   = "submit"
+	JournalCancel   = "cancel"
+	JournalReplace  = "replace"
+	JournalExercise = "exercise"
+)
+
+// A JournalEntry records one order action taken through the client.
+type JournalEntry struct {
+	Time   time.Time  `json:"time"`
+	Action string     `json:"action"`
+	Asset  AssetClass `json:"asset"`
+	// Tag is the caller-provided label set with WithJournalTag.
+	Tag      string          `json:"tag,omitempty"`
+	URL      string          `json:"url"`
+	OrderID  string          `json:"order_id,omitempty"`
+	RefID    string          `json:"ref_id,omitempty"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// State is the order's state as reported in the response.
+	State  string `json:"state,omitempty"`
+	Error  string `json:"error,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// A Journal receives an entry for every order submitted, replaced or
+// cancelled, and every option exercised, through a Client whose Journal field
+// is set.
+type Journal interface {
+	Record(JournalEntry) error
+}
+
+type journalTagKey struct{}
+
+// WithJournalTag returns a context that labels the journal entries of any
+// order actions taken with it.
+func WithJournalTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, journalTagKey{}, tag)
+}
+
+// journal records an entry in the client's Journal, if it has one. The
+// order ID and state are taken from resp when not already set. Failures to
+// record are logged, since the action itself has already happened.
+func (c *Client) journal(ctx context.Context, e JournalEntry, resp interface{}, err error) {
+	if c.Journal == nil {
+		return
+	}
+
+	e.Time = time.Now()
+	e.DryRun = c.DryRun
+	e.Tag, _ = ctx.Value(journalTagKey{}).(string)
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if bs, merr := json.Marshal(resp); merr == nil && string(bs) != "null" {
+		e.Response = bs
+
+		var o struct {
+			ID    string `json:"id"`
+			State string `json:"state"`
+		}
+		if json.Unmarshal(bs, &o) == nil {
+			if e.OrderID == "" {
+				e.OrderID = o.ID
+			}
+			e.State = o.State
+		}
+	}
+
+	if jerr := c.Journal.Record(e); jerr != nil {
+		c.logf("robinhood: could not journal %s of order %s: %v", e.Action, e.OrderID, jerr)
+	}
+}
+
+func (c *Client) journalCancel(ctx context.Context, asset AssetClass, id, url string, err error) {
+	c.journal(ctx, JournalEntry{
+		Action:  JournalCancel,
+		Asset:   asset,
+		URL:     url,
+		OrderID: id,
+	}, nil, err)
+}
+
+// JSONLJournal is a Journal that appends entries to a file as json lines.
+type JSONLJournal struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Record implements Journal. Each entry is synced to disk before Record
+// returns.
+func (j *JSONLJournal) Record(e JournalEntry) error {
+	bs, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// JournalQuery selects journal entries. Zero-valued fields match every
+// entry.
+type JournalQuery struct {
+	Since, Until time.Time
+	Action       string
+	Asset        AssetClass
+	Tag          string
+	OrderID      string
+	RefID        string
+}
+
+func (q JournalQuery) matches(e JournalEntry) bool {
+	switch {
+	case !q.Since.IsZero() && e.Time.Before(q.Since),
+		!q.Until.IsZero() && !e.Time.Before(q.Until),
+		q.Action != "" && q.Action != e.Action,
+		q.Asset != "" && q.Asset != e.Asset,
+		q.Tag != "" && q.Tag != e.Tag,
+		q.OrderID != "" && q.OrderID != e.OrderID,
+		q.RefID != "" && q.RefID != e.RefID:
+		return false
+	}
+	return true
+}
+
+// Query returns the entries matching q, oldest first.
+func (j *JSONLJournal) Query(q JournalQuery) ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []JournalEntry
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 16<<20)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return out, err
+		}
+		if q.matches(e) {
+			out = append(out, e)
+		}
+	}
+	return out, s.Err()
+}
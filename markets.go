@@ -0,0 +1,33 @@
+package robinhood
+
+import (
+	"context"
+	"time"
+)
+
+// MarketNYSE is the MIC of the New York Stock Exchange, whose hours govern
+// equity trading.
+const MarketNYSE = "XNYS"
+
+// MarketHours describes the trading hours of a market on a given day.
+type MarketHours struct {
+	Date              Date      `json:"date"`
+	IsOpen            bool      `json:"is_open"`
+	OpensAt           time.Time `json:"opens_at"`
+	ClosesAt          time.Time `json:"closes_at"`
+	ExtendedOpensAt   time.Time `json:"extended_opens_at"`
+	ExtendedClosesAt  time.Time `json:"extended_closes_at"`
+	NextOpenHours     string    `json:"next_open_hours"`
+	PreviousOpenHours string    `json:"previous_open_hours"`
+}
+
+// GetMarketHours returns the hours of the market with the given MIC on the
+// given day. Unlike the helpers in times.go, it knows about market holidays.
+func (c *Client) GetMarketHours(ctx context.Context, mic string, d Date) (*MarketHours, error) {
+	var h MarketHours
+	err := c.GetAndDecode(ctx, EPMarkets+mic+"/hours/"+d.String()+"/", &h)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
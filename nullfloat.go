@@ -0,0 +1,59 @@
+package robinhood
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// A NullFloat is a number that may be missing, such as the greeks of an
+// option that has not traded. It decodes from JSON numbers, numeric strings
+// and null; an empty string is treated as null.
+type NullFloat struct {
+	Float64 float64
+	Valid   bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *NullFloat) UnmarshalJSON(bs []byte) error {
+	*n = NullFloat{}
+
+	bs = bytes.TrimSpace(bs)
+	if bytes.Equal(bs, []byte("null")) {
+		return nil
+	}
+
+	if len(bs) > 0 && bs[0] == '"' {
+		var s string
+		if err := json.Unmarshal(bs, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return nil
+		}
+		bs = []byte(s)
+	}
+
+	f, err := strconv.ParseFloat(string(bs), 64)
+	if err != nil {
+		return err
+	}
+	n.Float64, n.Valid = f, true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullFloat) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatFloat(n.Float64, 'f', -1, 64)), nil
+}
+
+// String returns the number, or an empty string if it is missing.
+func (n NullFloat) String() string {
+	if !n.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(n.Float64, 'f', -1, 64)
+}
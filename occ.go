@@ -0,0 +1,113 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const occDateFormat = "060102"
+
+// An OCCSymbol identifies an option by the standard OCC symbology used by
+// other brokers and data vendors, e.g. "SPY   240119C00450000": the root
+// symbol padded to six characters, the expiration as YYMMDD, C or P, and the
+// strike in thousandths of a dollar padded to eight digits.
+type OCCSymbol struct {
+	Root       string
+	Expiration Date
+	// Type is OptionCall or OptionPut.
+	Type   string
+	Strike float64
+}
+
+// OCC returns the OCC symbol of the option.
+func (o *OptionInstrument) OCC() OCCSymbol {
+	return OCCSymbol{
+		Root:       o.ChainSymbol,
+		Expiration: o.ExpirationDate,
+		Type:       o.Type,
+		Strike:     o.StrikePrice,
+	}
+}
+
+// String formats the symbol in its padded, 21 character form.
+func (s OCCSymbol) String() string {
+	t := "C"
+	if s.Type == OptionPut {
+		t = "P"
+	}
+	return fmt.Sprintf("%-6s%s%s%08d", s.Root, s.Expiration.Format(occDateFormat), t, int64(math.Round(s.Strike*1000)))
+}
+
+// ParseOCC parses an OCC symbol. The root may be padded with spaces or not,
+// so both "SPY   240119C00450000" and "SPY240119C00450000" are accepted.
+func ParseOCC(sym string) (OCCSymbol, error) {
+	var s OCCSymbol
+
+	sym = strings.TrimSpace(sym)
+	if len(sym) < 16 {
+		return s, fmt.Errorf("invalid OCC symbol %q: too short", sym)
+	}
+	tail := sym[len(sym)-15:]
+
+	s.Root = strings.TrimSpace(sym[:len(sym)-15])
+	if s.Root == "" || len(s.Root) > 6 {
+		return s, fmt.Errorf("invalid OCC symbol %q: bad root", sym)
+	}
+
+	t, err := time.Parse(occDateFormat, tail[:6])
+	if err != nil {
+		return s, fmt.Errorf("invalid OCC symbol %q: bad expiration", sym)
+	}
+	s.Expiration = Date{t}
+
+	switch tail[6] {
+	case 'C':
+		s.Type = OptionCall
+	case 'P':
+		s.Type = OptionPut
+	default:
+		return s, fmt.Errorf("invalid OCC symbol %q: bad type", sym)
+	}
+
+	strike, err := strconv.ParseUint(tail[7:], 10, 64)
+	if err != nil {
+		return s, fmt.Errorf("invalid OCC symbol %q: bad strike", sym)
+	}
+	s.Strike = float64(strike) / 1000
+
+	return s, nil
+}
+
+// GetOptionInstrumentByOCC looks up the option instrument identified by an
+// OCC symbol.
+func (c *Client) GetOptionInstrumentByOCC(ctx context.Context, sym string) (*OptionInstrument, error) {
+	s, err := ParseOCC(sym)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("chain_symbol", s.Root)
+	v.Set("expiration_dates", s.Expiration.String())
+	v.Set("strike_price", strconv.FormatFloat(s.Strike, 'f', 4, 64))
+	v.Set("type", s.Type)
+
+	var out struct{ Results []*OptionInstrument }
+	if err := c.GetAndDecode(ctx, EPOptions+"instruments/?"+v.Encode(), &out); err != nil {
+		return nil, errors.Wrap(err, "could not look up option")
+	}
+
+	for _, o := range out.Results {
+		if o.OCC().String() == s.String() {
+			return o, nil
+		}
+	}
+	return nil, fmt.Errorf("no option found for %s", s)
+}
@@ -0,0 +1,34 @@
+package robinhood
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOCCSymbol(t *testing.T) {
+	asrt := assert.New(t)
+
+	s, err := ParseOCC("SPY   240119C00450000")
+	asrt.NoError(err)
+	asrt.Equal("SPY", s.Root)
+	asrt.Equal("2024-01-19", s.Expiration.String())
+	asrt.Equal(OptionCall, s.Type)
+	asrt.Equal(450.0, s.Strike)
+	asrt.Equal("SPY   240119C00450000", s.String())
+
+	s, err = ParseOCC("BRKB261218P00012500")
+	asrt.NoError(err)
+	asrt.Equal("BRKB", s.Root)
+	asrt.Equal(OptionPut, s.Type)
+	asrt.Equal(12.5, s.Strike)
+	asrt.Equal("BRKB  261218P00012500", s.String())
+
+	o := &OptionInstrument{ChainSymbol: "AAPL", ExpirationDate: NewDate(2025, 3, 21), Type: OptionPut, StrikePrice: 172.5}
+	asrt.Equal("AAPL  250321P00172500", o.OCC().String())
+
+	for _, bad := range []string{"", "SPY240119C0045000", "SPY   241319C00450000", "SPY   240119X00450000", "TOOLONG240119C00450000"} {
+		_, err := ParseOCC(bad)
+		asrt.Error(err, bad)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 // OptionsOrderOpts encapsulates common Options order choices
@@ -46,7 +51,7 @@ type Leg struct {
 // OrderOptions places a new order for options. Cancellation of the
 // context.Context will cancel the _http request_, never the order itself if it
 // has already been created.
-func (c *Client) OrderOptions(ctx context.Context, q *OptionInstrument, o OptionsOrderOpts) (json.RawMessage, error) {
+func (c *Client) OrderOptions(ctx context.Context, q *OptionInstrument, o OptionsOrderOpts) (*OptionOrderOutput, error) {
 	b := optionInput{
 		Account:     c.Account.URL,
 		Direction:   o.Direction,
@@ -55,7 +60,7 @@ func (c *Client) OrderOptions(ctx context.Context, q *OptionInstrument, o Option
 			Option:         q.URL,
 			RatioQuantity:  1,
 			Side:           o.Side,
-			PositionEffect: "open",
+			PositionEffect: EffectOpen,
 		}},
 		Trigger:  "immediate",
 		Type:     o.Type,
@@ -65,36 +70,220 @@ func (c *Client) OrderOptions(ctx context.Context, q *OptionInstrument, o Option
 	}
 
 	if o.Side != Buy {
-		b.Legs[0].PositionEffect = "close"
+		b.Legs[0].PositionEffect = EffectClose
 	}
 
+	return c.submitOptionsOrder(ctx, b)
+}
+
+// submitOptionsOrder sends an options order and journals the result.
+func (c *Client) submitOptionsOrder(ctx context.Context, b optionInput) (*OptionOrderOutput, error) {
 	bs, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
 	}
 
+	out, err := c.sendOptionsOrder(ctx, b, bs)
+	c.journal(ctx, JournalEntry{
+		Action:  JournalSubmit,
+		Asset:   AssetOption,
+		URL:     EPOptions + "orders/",
+		RefID:   b.RefID,
+		Request: bs,
+	}, out, err)
+	return out, err
+}
+
+func (c *Client) sendOptionsOrder(ctx context.Context, b optionInput, bs []byte) (*OptionOrderOutput, error) {
+	if c.DryRun {
+		c.logDryRun("POST", EPOptions+"orders/", bs)
+		return dryRunOptionOrder(b), nil
+	}
+
 	req, err := http.NewRequest("POST", EPOptions+"orders/", bytes.NewReader(bs))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	var out json.RawMessage
+	var out OptionOrderOutput
 	err = c.DoAndDecode(ctx, req, &out)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return &out, nil
+}
+
+// OptionOrderOutput is the response from the options order api.
+type OptionOrderOutput struct {
+	ID                string           `json:"id"`
+	RefID             string           `json:"ref_id"`
+	AccountNumber     string           `json:"account_number"`
+	CancelURL         string           `json:"cancel_url"`
+	ChainID           string           `json:"chain_id"`
+	ChainSymbol       string           `json:"chain_symbol"`
+	State             string           `json:"state"`
+	Type              string           `json:"type"`
+	TimeInForce       string           `json:"time_in_force"`
+	Trigger           string           `json:"trigger"`
+	Direction         string           `json:"direction"`
+	Legs              []OptionOrderLeg `json:"legs"`
+	Price             float64          `json:"price,string"`
+	StopPrice         float64          `json:"stop_price,string"`
+	Premium           float64          `json:"premium,string"`
+	ProcessedPremium  float64          `json:"processed_premium,string"`
+	Quantity          float64          `json:"quantity,string"`
+	ProcessedQuantity float64          `json:"processed_quantity,string"`
+	PendingQuantity   float64          `json:"pending_quantity,string"`
+	CanceledQuantity  float64          `json:"canceled_quantity,string"`
+	OpeningStrategy   string           `json:"opening_strategy"`
+	ClosingStrategy   string           `json:"closing_strategy"`
+	ResponseCategory  string           `json:"response_category"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// OptionOrderLeg is a single leg of an options order as reported by the api.
+type OptionOrderLeg struct {
+	ID             string      `json:"id"`
+	Option         string      `json:"option"`
+	PositionEffect string      `json:"position_effect"`
+	RatioQuantity  float64     `json:"ratio_quantity"`
+	Side           string      `json:"side"`
+	ExpirationDate string      `json:"expiration_date"`
+	StrikePrice    float64     `json:"strike_price,string"`
+	OptionType     string      `json:"option_type"`
+	Executions     []Execution `json:"executions"`
+}
+
+// IsDone reports whether the order has reached a final state.
+func (o OptionOrderOutput) IsDone() bool {
+	return isDoneState(o.State)
+}
+
+// Update returns any errors and updates the item with any recent changes.
+// Synthetic dry-run orders have nothing to fetch and are left unchanged.
+func (o *OptionOrderOutput) Update(ctx context.Context, c *Client) error {
+	if o.State == OrderStateDryRun {
+		return nil
+	}
+	return c.GetAndDecode(ctx, EPOptions+"orders/"+o.ID+"/", o)
+}
+
+// Wait calls Update every interval until the order reaches a final state or
+// the context is cancelled.
+func (o *OptionOrderOutput) Wait(ctx context.Context, c *Client, interval time.Duration) error {
+	for !o.IsDone() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if err := o.Update(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cancel attempts to cancel the order.
+func (o OptionOrderOutput) Cancel(ctx context.Context, c *Client) error {
+	return c.cancelOptionsOrder(ctx, o.ID, o.CancelURL)
+}
+
+// CancelOptionsOrderById attempts to cancel the options order with the given
+// ID.
+func (c *Client) CancelOptionsOrderById(ctx context.Context, id string) error {
+	return c.cancelOptionsOrder(ctx, id, EPOptions+"orders/"+id+"/cancel/")
+}
+
+// cancelOptionsOrder posts to the cancel URL of an options order.
+func (c *Client) cancelOptionsOrder(ctx context.Context, id, cancelURL string) (err error) {
+	defer func() {
+		c.journalCancel(ctx, AssetOption, id, cancelURL, err)
+	}()
+
+	if cancelURL == "" {
+		return errors.New("order cannot be cancelled")
+	}
+
+	if c.DryRun {
+		c.logDryRun("POST", cancelURL, nil)
+		return nil
+	}
+
+	post, err := http.NewRequest("POST", cancelURL, nil)
+	if err != nil {
+		return err
+	}
+
+	var out struct{}
+	return c.DoAndDecode(ctx, post, &out)
 }
 
-// GetOptionsOrders returns all outstanding options orders
-func (c *Client) GetOptionsOrders(ctx context.Context) (json.RawMessage, error) {
-	var o json.RawMessage
-	err := c.GetAndDecode(ctx, EPOptions+"orders/", &o)
+// OptionOrderFilter selects options orders. Zero-valued fields match every
+// order.
+type OptionOrderFilter struct {
+	State       string
+	ChainSymbol string
+}
+
+// GetOptionsOrders returns a page of options orders matching the filter,
+// along with the URL of the next page, if any. Pass that URL back as nextUrl
+// to continue.
+func (c *Client) GetOptionsOrders(ctx context.Context, nextUrl *string, pgSize int64, filter OptionOrderFilter) ([]OptionOrderOutput, string, error) {
+	v := url.Values{}
+	if pgSize != 0 {
+		v.Set("page_size", fmt.Sprint(pgSize))
+	}
+	if filter.State != "" {
+		v.Set("state", filter.State)
+	}
+
+	u := EPOptions + "orders/"
+	if len(v) > 0 {
+		u += "?" + v.Encode()
+	}
+	if nextUrl != nil {
+		u = *nextUrl
+	}
+
+	var o struct {
+		Results []OptionOrderOutput
+		Next    string
+	}
+	err := c.GetAndDecode(ctx, u, &o)
 	if err != nil {
-		return nil, err
+		return nil, o.Next, err
+	}
+
+	if filter.ChainSymbol == "" {
+		return o.Results, o.Next, nil
 	}
 
-	return o, nil
+	out := o.Results[:0]
+	for _, r := range o.Results {
+		if strings.EqualFold(r.ChainSymbol, filter.ChainSymbol) {
+			out = append(out, r)
+		}
+	}
+	return out, o.Next, nil
+}
 
+// AllOptionsOrders returns every options order matching the filter.
+func (c *Client) AllOptionsOrders(ctx context.Context, filter OptionOrderFilter) ([]OptionOrderOutput, error) {
+	var all []OptionOrderOutput
+	var next *string
+	for {
+		rs, n, err := c.GetOptionsOrders(ctx, next, 100, filter)
+		all = append(all, rs...)
+		if err != nil {
+			return all, err
+		}
+		if n == "" {
+			return all, nil
+		}
+		next = &n
+	}
 }
@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
 )
 
 const dateFormat = "2006-01-02"
@@ -98,11 +100,11 @@ func (p *Pager) GetNext(ctx context.Context, c *Client, out interface{}) error {
 	return c.GetAndDecode(ctx, p.Next, out)
 }
 
-// GetInstrument returns a list of option-typed instruments given a list of
-// expiration dates for a given trade type. The request will continue until the
-// provided context is cancelled. This is done to mimic the way the web UI
-// fetches many, many options instruments repeatedly, since I haven't yet
-// figured out how/when they decide to stop.
+// GetInstrument returns the active, tradable option instruments of the given
+// trade type ("call" or "put") that expire on date, following every page of
+// results. If the context is cancelled part way through, the instruments
+// fetched so far are returned with its error. Use GetFullChain for all
+// expirations at once.
 func (o *OptionChain) GetInstrument(ctx context.Context, tradeType string, date Date) ([]*OptionInstrument, error) {
 	u := fmt.Sprintf(
 		"%sinstruments/?chain_id=%s&expiration_dates=%s&state=active&tradability=tradable&type=%s",
@@ -113,29 +115,25 @@ func (o *OptionChain) GetInstrument(ctx context.Context, tradeType string, date
 	)
 
 	var rs []*OptionInstrument
-	var out struct {
-		Results []*OptionInstrument
-		Pager
-	}
-	err := o.c.GetAndDecode(ctx, u, &out)
-	if err != nil {
-		return nil, err
-	}
-
-	rs = append(rs, out.Results...)
-
-	for out.HasMore() {
+	for u != "" {
 		select {
 		case <-ctx.Done():
 			return rs, ctx.Err()
 		default:
 		}
 
-		err := out.GetNext(ctx, o.c, &out)
-		if err != nil {
+		// Each page is decoded fresh: a null "next" on the last page would
+		// leave a reused Pager pointing at the previous page.
+		var out struct {
+			Results []*OptionInstrument
+			Pager
+		}
+		if err := o.c.GetAndDecode(ctx, u, &out); err != nil {
 			return rs, err
 		}
+
 		rs = append(rs, out.Results...)
+		u = out.Next
 	}
 	return rs, nil
 }
@@ -178,30 +176,30 @@ type OptionInstrument struct {
 // MarketData is the current pricing data and greeks for a given option at a
 // given time.
 type MarketData struct {
-	AdjustedMarkPrice   float64 `json:"adjusted_mark_price,string"`
-	AskPrice            float64 `json:"ask_price,string"`
-	AskSize             int     `json:"ask_size"`
-	BidPrice            float64 `json:"bid_price,string"`
-	BidSize             int     `json:"bid_size"`
-	BreakEvenPrice      float64 `json:"break_even_price,string"`
-	ChanceOfProfitLong  float64 `json:"chance_of_profit_long,string"`
-	ChanceOfProfitShort float64 `json:"chance_of_profit_short,string"`
-	Delta               float64 `json:"delta,string"`
-	Gamma               float64 `json:"gamma,string"`
-	HighPrice           float64 `json:"high_price,string"`
-	ImpliedVolatility   string  `json:"implied_volatility"`
-	Instrument          string  `json:"instrument"`
-	LastTradePrice      float64 `json:"last_trade_price,string"`
-	LastTradeSize       int     `json:"last_trade_size"`
-	LowPrice            float64 `json:"low_price,string"`
-	MarkPrice           float64 `json:"mark_price,string"`
-	OpenInterest        int     `json:"open_interest"`
-	PreviousCloseDate   Date    `json:"previous_close_date"`
-	PreviousClosePrice  float64 `json:"previous_close_price,string"`
-	Rho                 string  `json:"rho"`
-	Theta               string  `json:"theta"`
-	Vega                string  `json:"vega"`
-	Volume              int     `json:"volume"`
+	AdjustedMarkPrice   float64   `json:"adjusted_mark_price,string"`
+	AskPrice            float64   `json:"ask_price,string"`
+	AskSize             int       `json:"ask_size"`
+	BidPrice            float64   `json:"bid_price,string"`
+	BidSize             int       `json:"bid_size"`
+	BreakEvenPrice      float64   `json:"break_even_price,string"`
+	ChanceOfProfitLong  float64   `json:"chance_of_profit_long,string"`
+	ChanceOfProfitShort float64   `json:"chance_of_profit_short,string"`
+	Delta               NullFloat `json:"delta"`
+	Gamma               NullFloat `json:"gamma"`
+	HighPrice           float64   `json:"high_price,string"`
+	ImpliedVolatility   NullFloat `json:"implied_volatility"`
+	Instrument          string    `json:"instrument"`
+	LastTradePrice      float64   `json:"last_trade_price,string"`
+	LastTradeSize       int       `json:"last_trade_size"`
+	LowPrice            float64   `json:"low_price,string"`
+	MarkPrice           float64   `json:"mark_price,string"`
+	OpenInterest        int       `json:"open_interest"`
+	PreviousCloseDate   Date      `json:"previous_close_date"`
+	PreviousClosePrice  float64   `json:"previous_close_price,string"`
+	Rho                 NullFloat `json:"rho"`
+	Theta               NullFloat `json:"theta"`
+	Vega                NullFloat `json:"vega"`
+	Volume              int       `json:"volume"`
 }
 
 // OIsForDate filters OptionInstruments for expiration date.
@@ -215,7 +213,35 @@ func OIsForDate(os []*OptionInstrument, d Date) []*OptionInstrument {
 	return out
 }
 
-// MarketData returns market data for all the listed Option instruments
+// marketDataBatch is the number of instruments requested at once, and
+// marketDataWorkers the number of batches requested concurrently. Requests
+// are still spaced out by the client's throttle.
+const (
+	marketDataBatch   = 30
+	marketDataWorkers = 4
+)
+
+// A MarketDataError is returned by MarketData along with the results that
+// were fetched when some batches of instruments fail.
+type MarketDataError struct {
+	// Failed lists the URLs of the instruments whose batch failed.
+	Failed []string
+	Err    error
+}
+
+func (e *MarketDataError) Error() string {
+	return fmt.Sprintf("could not get market data for %d instruments: %v", len(e.Failed), e.Err)
+}
+
+// Cause returns the underlying error.
+func (e *MarketDataError) Cause() error {
+	return e.Err
+}
+
+// MarketData returns market data for all the listed Option instruments, in
+// the order they are listed. Instruments with no market data are left out.
+// If some requests fail, the data that was fetched is returned with a
+// *MarketDataError.
 func (c *Client) MarketData(ctx context.Context, opts ...*OptionInstrument) ([]*MarketData, error) {
 	is := make([]string, len(opts))
 
@@ -228,37 +254,63 @@ func (c *Client) MarketData(ctx context.Context, opts ...*OptionInstrument) ([]*
 		return nil, shameWrap(err, "couldn't parse URL const EPOptionQuote")
 	}
 
-	// Number of instruments to request at once
-	num := 30
-	// Number of requests this will require to be made
-	n := len(is) / num
-	if len(is)%num != 0 {
-		n++
-	}
+	found := make([]*MarketData, len(is))
+	failed := make([]bool, len(is))
 
-	rs := []*MarketData{}
+	var mu sync.Mutex
+	var errs error
 
-	for i := 0; i < n; i++ {
-		end := (i+1)*num + 1
+	var eg errgroup.Group
+	eg.SetLimit(marketDataWorkers)
+	for start := 0; start < len(is); start += marketDataBatch {
+		end := start + marketDataBatch
 		if end > len(is) {
 			end = len(is)
 		}
+		start, batch := start, is[start:end]
+
+		eg.Go(func() error {
+			bu := *u
+			bu.RawQuery = url.Values{"instruments": []string{strings.Join(batch, ",")}}.Encode()
+
+			var r struct{ Results []*MarketData }
+			if err := c.GetAndDecode(ctx, bu.String(), &r); err != nil {
+				mu.Lock()
+				errs = multierror.Append(errs, err)
+				mu.Unlock()
+				for i := range batch {
+					failed[start+i] = true
+				}
+				return nil
+			}
 
-		q := url.Values{"instruments": []string{strings.Join(is[i*num:end], ",")}}
-
-		u.RawQuery = q.Encode()
+			byURL := make(map[string]*MarketData, len(r.Results))
+			for _, res := range r.Results {
+				if res != nil {
+					byURL[res.Instrument] = res
+				}
+			}
+			for i, inst := range batch {
+				found[start+i] = byURL[inst]
+			}
+			return nil
+		})
+	}
+	eg.Wait()
 
-		var r struct{ Results []*MarketData }
-		if e := c.GetAndDecode(ctx, u.String(), &r); err != nil {
-			err = multierror.Append(err, e)
-			continue
+	rs := make([]*MarketData, 0, len(found))
+	merr := &MarketDataError{Err: errs}
+	for i, md := range found {
+		if md != nil {
+			rs = append(rs, md)
 		}
-		for _, res := range r.Results {
-			if res != nil {
-				rs = append(rs, res)
-			}
+		if failed[i] {
+			merr.Failed = append(merr.Failed, is[i])
 		}
 	}
 
-	return rs, err
+	if len(merr.Failed) > 0 {
+		return rs, merr
+	}
+	return rs, nil
 }
@@ -0,0 +1,210 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Option types.
+const (
+	OptionCall = "call"
+	OptionPut  = "put"
+)
+
+// Moneyness selects options by how their strike compares to the price of the
+// underlying.
+type Moneyness int
+
+// Moneyness choices. An option struck exactly at the spot price counts as out
+// of the money.
+const (
+	AnyMoneyness Moneyness = iota
+	InTheMoney
+	OutOfTheMoney
+)
+
+// ChainFilter narrows the options returned by GetFullChain. Zero-valued fields
+// do not filter.
+type ChainFilter struct {
+	// MinDTE and MaxDTE bound the number of calendar days to expiration.
+	MinDTE, MaxDTE int
+	// StrikeWindow keeps only strikes within this fraction of the spot
+	// price, e.g. 0.1 for strikes within 10% either side.
+	StrikeWindow float64
+	Moneyness    Moneyness
+	// Types limits the option types fetched to OptionCall or OptionPut.
+	// Empty fetches both.
+	Types []string
+	// Spot is the price of the underlying to filter against. If zero, the
+	// underlying is quoted.
+	Spot float64
+	// Concurrency bounds the number of requests in flight. Defaults to 4.
+	Concurrency int
+}
+
+func (f ChainFilter) keepsDTE(dte int) bool {
+	return dte >= f.MinDTE && (f.MaxDTE == 0 || dte <= f.MaxDTE)
+}
+
+func (f ChainFilter) keeps(o *OptionInstrument, spot float64) bool {
+	if f.StrikeWindow > 0 && (o.StrikePrice < spot*(1-f.StrikeWindow) || o.StrikePrice > spot*(1+f.StrikeWindow)) {
+		return false
+	}
+
+	itm := o.Type == OptionCall && o.StrikePrice < spot || o.Type == OptionPut && o.StrikePrice > spot
+	switch f.Moneyness {
+	case InTheMoney:
+		return itm
+	case OutOfTheMoney:
+		return !itm
+	}
+	return true
+}
+
+// daysToExpiration counts the calendar days from now, in New York, to an
+// expiration date.
+func daysToExpiration(d Date, now time.Time) int {
+	now = now.In(nyLoc())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	exp := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	return int(exp.Sub(today).Hours() / 24)
+}
+
+// A FullChain holds the option instruments of a chain indexed by expiration
+// date ("2006-01-02"), then strike, then type.
+type FullChain struct {
+	Chain       *OptionChain
+	Spot        float64
+	Expirations map[string]map[float64]map[string]*OptionInstrument
+}
+
+// Dates returns the chain's expiration dates in order.
+func (fc *FullChain) Dates() []string {
+	ds := make([]string, 0, len(fc.Expirations))
+	for d := range fc.Expirations {
+		ds = append(ds, d)
+	}
+	sort.Strings(ds)
+	return ds
+}
+
+// Strikes returns the strikes listed for an expiration date in order.
+func (fc *FullChain) Strikes(date string) []float64 {
+	ss := make([]float64, 0, len(fc.Expirations[date]))
+	for s := range fc.Expirations[date] {
+		ss = append(ss, s)
+	}
+	sort.Float64s(ss)
+	return ss
+}
+
+// Get returns the option with the given expiration date, strike and type, or
+// nil.
+func (fc *FullChain) Get(date string, strike float64, typ string) *OptionInstrument {
+	return fc.Expirations[date][strike][typ]
+}
+
+// Instruments returns every option in the chain, ordered by expiration,
+// strike and type.
+func (fc *FullChain) Instruments() []*OptionInstrument {
+	var out []*OptionInstrument
+	for _, d := range fc.Dates() {
+		for _, s := range fc.Strikes(d) {
+			for _, typ := range []string{OptionCall, OptionPut} {
+				if o := fc.Get(d, s, typ); o != nil {
+					out = append(out, o)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// GetFullChain fetches the options of every expiration of the chain, calls
+// and puts, that pass the filter.
+func (c *Client) GetFullChain(ctx context.Context, chain *OptionChain, f ChainFilter) (*FullChain, error) {
+	fc := &FullChain{
+		Chain:       chain,
+		Spot:        f.Spot,
+		Expirations: map[string]map[float64]map[string]*OptionInstrument{},
+	}
+
+	if fc.Spot == 0 && (f.StrikeWindow > 0 || f.Moneyness != AnyMoneyness) {
+		qs, err := c.GetQuote(ctx, chain.Symbol)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not quote underlying")
+		}
+		if len(qs) < 1 || qs[0].Price() == 0 {
+			return nil, fmt.Errorf("no price for %s", chain.Symbol)
+		}
+		fc.Spot = qs[0].Price()
+	}
+
+	types := f.Types
+	if len(types) == 0 {
+		types = []string{OptionCall, OptionPut}
+	}
+
+	now := time.Now()
+	var dates []Date
+	for _, ds := range chain.ExpirationDates {
+		t, err := time.Parse(dateFormat, ds)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid expiration date %q", ds)
+		}
+		if f.keepsDTE(daysToExpiration(Date{t}, now)) {
+			dates = append(dates, Date{t})
+		}
+	}
+
+	o := *chain
+	o.c = c
+
+	n := f.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+
+	var mu sync.Mutex
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(n)
+	for _, date := range dates {
+		for _, typ := range types {
+			date, typ := date, typ
+			eg.Go(func() error {
+				insts, err := o.GetInstrument(ctx, typ, date)
+				if err != nil {
+					return errors.Wrapf(err, "could not get %s %s options", date, typ)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, inst := range insts {
+					if !f.keeps(inst, fc.Spot) {
+						continue
+					}
+					d := inst.ExpirationDate.String()
+					if fc.Expirations[d] == nil {
+						fc.Expirations[d] = map[float64]map[string]*OptionInstrument{}
+					}
+					if fc.Expirations[d][inst.StrikePrice] == nil {
+						fc.Expirations[d][inst.StrikePrice] = map[string]*OptionInstrument{}
+					}
+					fc.Expirations[d][inst.StrikePrice][inst.Type] = inst
+				}
+				return nil
+			})
+		}
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
@@ -0,0 +1,79 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// GetOptionInstrument returns the option instrument at the given URL, such as
+// the Option of a LegPosition.
+func (c *Client) GetOptionInstrument(ctx context.Context, instURL string) (*OptionInstrument, error) {
+	var i OptionInstrument
+	err := c.GetAndDecode(ctx, instURL, &i)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ClosingSpread returns the spread that closes every leg of pos, and the
+// number of spreads to trade to close it entirely.
+func (c *Client) ClosingSpread(ctx context.Context, pos OptionPostion) (OptionSpread, float64, error) {
+	var s OptionSpread
+
+	qty, err := strconv.ParseFloat(pos.Quantity, 64)
+	if err != nil {
+		return s, 0, errors.Wrap(err, "invalid position quantity")
+	}
+	qty = math.Abs(qty)
+	if qty == 0 {
+		return s, 0, errors.New("position is already closed")
+	}
+
+	for _, l := range pos.Legs {
+		ratio, err := strconv.ParseFloat(l.RatioQuantity, 64)
+		if err != nil {
+			return s, 0, errors.Wrap(err, "invalid leg ratio")
+		}
+
+		inst, err := c.GetOptionInstrument(ctx, l.Option)
+		if err != nil {
+			return s, 0, errors.Wrap(err, "could not look up option")
+		}
+
+		leg := SpreadLeg{Instrument: inst, Ratio: ratio, Effect: EffectClose}
+		switch l.PositionType {
+		case "long":
+			leg.Side = Sell
+		case "short":
+			leg.Side = Buy
+		default:
+			return s, 0, fmt.Errorf("unknown position type %q", l.PositionType)
+		}
+		s.Legs = append(s.Legs, leg)
+	}
+
+	return s, qty, nil
+}
+
+// ClosePosition closes an options position with a single day limit order for
+// all of its legs. If price is zero, the order is priced at the net of the
+// legs' current marks. Whether the order is a debit or a credit is worked out
+// from the marks as well.
+func (c *Client) ClosePosition(ctx context.Context, pos OptionPostion, price float64) (*OptionOrderOutput, error) {
+	s, qty, err := c.ClosingSpread(ctx, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.OrderSpread(ctx, s, SpreadOrderOpts{
+		Quantity:    qty,
+		Price:       price,
+		TimeInForce: GFD,
+		Type:        Limit,
+	})
+}
@@ -0,0 +1,246 @@
+package robinhood
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Types of OptionEvent.
+const (
+	OptionEventExercise   = "exercise"
+	OptionEventAssignment = "assignment"
+	OptionEventExpiration = "expiration"
+)
+
+// An OptionExercise is a request to exercise long options.
+type OptionExercise struct {
+	ID        string    `json:"id"`
+	RefID     string    `json:"ref_id"`
+	Account   string    `json:"account"`
+	Option    string    `json:"option"`
+	Quantity  float64   `json:"quantity,string"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExercisePosition asks to exercise quantity contracts of a long,
+// single-leg option position. A quantity of zero exercises the whole
+// position. The exercise is processed by Robinhood after the close, and
+// shows up as an OptionEvent once it has been.
+func (c *Client) ExercisePosition(ctx context.Context, pos OptionPostion, quantity float64) (out *OptionExercise, err error) {
+	if len(pos.Legs) != 1 {
+		return nil, fmt.Errorf("can only exercise single-leg positions, %s has %d legs", pos.Symbol, len(pos.Legs))
+	}
+	l := pos.Legs[0]
+	if l.PositionType != "long" {
+		return nil, errors.New("can only exercise long options")
+	}
+
+	held, err := strconv.ParseFloat(pos.Quantity, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid position quantity")
+	}
+	if quantity == 0 {
+		quantity = held
+	}
+	if quantity <= 0 || quantity > held {
+		return nil, fmt.Errorf("cannot exercise %g of %g contracts", quantity, held)
+	}
+
+	b := OptionExercise{
+		RefID:    uuid.New().String(),
+		Account:  c.Account.URL,
+		Option:   l.Option,
+		Quantity: quantity,
+	}
+	bs, err := json.Marshal(struct {
+		Account  string  `json:"account"`
+		Option   string  `json:"option"`
+		Quantity float64 `json:"quantity,string"`
+		RefID    string  `json:"ref_id"`
+	}{b.Account, b.Option, b.Quantity, b.RefID})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		c.journal(ctx, JournalEntry{
+			Action:  JournalExercise,
+			Asset:   AssetOption,
+			URL:     EPOptions + "exercises/",
+			RefID:   b.RefID,
+			Request: bs,
+		}, out, err)
+	}()
+
+	if c.DryRun {
+		c.logDryRun("POST", EPOptions+"exercises/", bs)
+		b.ID = uuid.New().String()
+		b.State = OrderStateDryRun
+		b.CreatedAt = time.Now()
+		b.UpdatedAt = b.CreatedAt
+		return &b, nil
+	}
+
+	req, err := http.NewRequest("POST", EPOptions+"exercises/", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var ex OptionExercise
+	if err := c.DoAndDecode(ctx, req, &ex); err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// An EquityComponent is a change in a stock position caused by an
+// OptionEvent.
+type EquityComponent struct {
+	ID         string  `json:"id"`
+	Instrument string  `json:"instrument"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Price      float64 `json:"price,string"`
+	Quantity   float64 `json:"quantity,string"`
+}
+
+// Shares is the number of shares the component adds to the position, or
+// removes if negative.
+func (e EquityComponent) Shares() float64 {
+	if e.Side == "sell" {
+		return -e.Quantity
+	}
+	return e.Quantity
+}
+
+// An OptionEvent is the exercise, assignment or expiration of options held in
+// an account.
+type OptionEvent struct {
+	ID               string            `json:"id"`
+	Account          string            `json:"account"`
+	ChainID          string            `json:"chain_id"`
+	Option           string            `json:"option"`
+	Position         string            `json:"position"`
+	Type             string            `json:"type"`
+	State            string            `json:"state"`
+	Direction        string            `json:"direction"`
+	EventDate        Date              `json:"event_date"`
+	Quantity         float64           `json:"quantity,string"`
+	UnderlyingPrice  float64           `json:"underlying_price,string"`
+	TotalCashAmount  float64           `json:"total_cash_amount,string"`
+	EquityComponents []EquityComponent `json:"equity_components"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// OptionEventFilter selects option events. Zero-valued fields match every
+// event.
+type OptionEventFilter struct {
+	// Type is one of OptionEventExercise, OptionEventAssignment or
+	// OptionEventExpiration.
+	Type  string
+	Since time.Time
+}
+
+// GetOptionEvents returns every option event matching the filter, newest
+// first.
+func (c *Client) GetOptionEvents(ctx context.Context, f OptionEventFilter) ([]OptionEvent, error) {
+	v := url.Values{}
+	if f.Type != "" {
+		v.Set("type", f.Type)
+	}
+	if !f.Since.IsZero() {
+		v.Set("updated_at[gte]", f.Since.UTC().Format(time.RFC3339))
+	}
+
+	u := EPOptions + "events/?" + v.Encode()
+
+	var out []OptionEvent
+	for u != "" {
+		var page struct {
+			Results []OptionEvent
+			Pager
+		}
+		if err := c.GetAndDecode(ctx, u, &page); err != nil {
+			return out, errors.Wrap(err, "could not get option events")
+		}
+
+		for _, e := range page.Results {
+			if f.Type != "" && e.Type != f.Type || !f.Since.IsZero() && e.UpdatedAt.Before(f.Since) {
+				continue
+			}
+			out = append(out, e)
+		}
+		u = page.Next
+	}
+	return out, nil
+}
+
+// An EquityChange is the net effect of option events on one stock position.
+type EquityChange struct {
+	Instrument string
+	Symbol     string
+	// Shares is the net number of shares the events added to the position,
+	// or removed if negative.
+	Shares float64
+	// Cash is the net cash the events added to the account, or took out if
+	// negative.
+	Cash float64
+	// Held is the size of the position now.
+	Held   float64
+	Events []string
+}
+
+// ReconcileOptionEvents totals the stock changes caused by option events per
+// instrument and pairs them with the current size of each stock position, in
+// order of symbol. Events that are not yet confirmed still count.
+func (c *Client) ReconcileOptionEvents(ctx context.Context, events []OptionEvent) ([]EquityChange, error) {
+	changes := map[string]*EquityChange{}
+	for _, e := range events {
+		for _, ec := range e.EquityComponents {
+			ch, ok := changes[ec.Instrument]
+			if !ok {
+				ch = &EquityChange{Instrument: ec.Instrument, Symbol: ec.Symbol}
+				changes[ec.Instrument] = ch
+			}
+			ch.Shares += ec.Shares()
+			ch.Cash -= ec.Shares() * ec.Price
+			ch.Events = append(ch.Events, e.ID)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	ps, err := c.GetPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get positions")
+	}
+	for _, p := range ps {
+		if ch, ok := changes[p.Instrument]; ok {
+			ch.Held = p.Quantity
+		}
+	}
+
+	out := make([]EquityChange, 0, len(changes))
+	for _, ch := range changes {
+		out = append(out, *ch)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out, nil
+}
@@ -0,0 +1,239 @@
+package robinhood
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// An ExpiringLeg is one leg of an options position that expires soon.
+type ExpiringLeg struct {
+	Position   OptionPostion
+	Leg        LegPosition
+	Symbol     string
+	Expiration Date
+	DTE        int
+	Type       string
+	Strike     float64
+	Short      bool
+	// Contracts is the number of contracts held in the leg.
+	Contracts float64
+	// Spot is the price of the underlying, and Mark and Delta those of the
+	// option.
+	Spot  float64
+	Mark  float64
+	Delta NullFloat
+	ITM   bool
+	// AtRisk is set for short legs that are in the money and so may be
+	// assigned.
+	AtRisk bool
+	// Shares is the number of shares received, or delivered if negative,
+	// if the leg is exercised or assigned, and Cash the cash received, or
+	// paid if negative. Both are zero for legs out of the money.
+	Shares float64
+	Cash   float64
+}
+
+// An ExpirationReport lists the option legs expiring within a number of days
+// and what would happen to the account if those in the money were exercised
+// or assigned.
+type ExpirationReport struct {
+	Time   time.Time
+	Within int
+	Legs   []ExpiringLeg
+	// Shares is the net number of shares received, or delivered if
+	// negative, per underlying.
+	Shares map[string]float64
+	// CashRequired is the cash needed to settle every in-the-money leg that
+	// buys shares.
+	CashRequired float64
+	BuyingPower  float64
+	// Shortfall is how far CashRequired exceeds BuyingPower.
+	Shortfall float64
+}
+
+// GetExpirationReport reports on the legs of open option positions that
+// expire within the given number of days. An option is taken to be in the
+// money by comparing its strike to the current price of the underlying.
+func (c *Client) GetExpirationReport(ctx context.Context, within int) (*ExpirationReport, error) {
+	r := &ExpirationReport{Time: time.Now(), Within: within, Shares: map[string]float64{}}
+
+	ps, err := c.GetOptionPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get option positions")
+	}
+
+	syms := map[string]bool{}
+	var insts []*OptionInstrument
+	for _, p := range ps {
+		qty, err := strconv.ParseFloat(p.Quantity, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid quantity for %s position", p.Symbol)
+		}
+
+		for _, l := range p.Legs {
+			t, err := time.Parse(dateFormat, l.ExpirationDate)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid expiration for %s position", p.Symbol)
+			}
+			exp := Date{t}
+			dte := daysToExpiration(exp, r.Time)
+			if dte < 0 || dte > within {
+				continue
+			}
+
+			strike, err := strconv.ParseFloat(l.StrikePrice, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid strike for %s position", p.Symbol)
+			}
+			ratio, err := strconv.ParseFloat(l.RatioQuantity, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid leg ratio for %s position", p.Symbol)
+			}
+
+			r.Legs = append(r.Legs, ExpiringLeg{
+				Position:   p,
+				Leg:        l,
+				Symbol:     p.Symbol,
+				Expiration: exp,
+				DTE:        dte,
+				Type:       l.OptionType,
+				Strike:     strike,
+				Short:      l.PositionType == "short",
+				Contracts:  qty * ratio,
+			})
+			syms[p.Symbol] = true
+			insts = append(insts, &OptionInstrument{URL: l.Option})
+		}
+	}
+
+	accts, err := c.GetAccounts(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get account")
+	}
+	if len(accts) > 0 {
+		r.BuyingPower = accts[0].BuyingPower
+	}
+
+	if len(r.Legs) == 0 {
+		return r, nil
+	}
+
+	symList := make([]string, 0, len(syms))
+	for s := range syms {
+		symList = append(symList, s)
+	}
+	qs, err := c.GetQuote(ctx, symList...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get quotes")
+	}
+	spots := map[string]float64{}
+	for _, q := range qs {
+		spots[q.Symbol] = q.Price()
+		if spots[q.Symbol] == 0 {
+			spots[q.Symbol] = q.LastTradePrice
+		}
+	}
+
+	mds, err := c.MarketData(ctx, insts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get market data")
+	}
+	byURL := map[string]*MarketData{}
+	for _, md := range mds {
+		byURL[md.Instrument] = md
+	}
+
+	for i := range r.Legs {
+		l := &r.Legs[i]
+
+		if md := byURL[l.Leg.Option]; md != nil {
+			l.Mark = md.MarkPrice
+			l.Delta = md.Delta
+		}
+
+		spot, ok := spots[l.Symbol]
+		if !ok {
+			return nil, fmt.Errorf("no quote for %s", l.Symbol)
+		}
+		l.Spot = spot
+		l.ITM = l.Type == OptionCall && spot > l.Strike || l.Type == OptionPut && spot < l.Strike
+		l.AtRisk = l.Short && l.ITM
+		if !l.ITM {
+			continue
+		}
+
+		mult, err := strconv.ParseFloat(l.Position.TradeValueMultiplier, 64)
+		if err != nil || mult == 0 {
+			mult = 100
+		}
+		l.Shares = l.Contracts * mult
+		if l.Type == OptionPut {
+			l.Shares = -l.Shares
+		}
+		if l.Short {
+			l.Shares = -l.Shares
+		}
+		l.Cash = -l.Shares * l.Strike
+
+		r.Shares[l.Symbol] += l.Shares
+		if l.Cash < 0 {
+			r.CashRequired -= l.Cash
+		}
+	}
+
+	if r.CashRequired > r.BuyingPower {
+		r.Shortfall = r.CashRequired - r.BuyingPower
+	}
+
+	sort.SliceStable(r.Legs, func(i, j int) bool {
+		a, b := r.Legs[i], r.Legs[j]
+		if a.DTE != b.DTE {
+			return a.DTE < b.DTE
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Strike < b.Strike
+	})
+
+	return r, nil
+}
+
+// String renders the report as a table of the expiring legs followed by the
+// account totals.
+func (r *ExpirationReport) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "SYMBOL\tEXPIRATION\tDTE\tTYPE\tSTRIKE\tQTY\tSPOT\tMARK\tITM\tSHARES\tCASH\n")
+	for _, l := range r.Legs {
+		qty := l.Contracts
+		if l.Short {
+			qty = -qty
+		}
+		itm := ""
+		if l.AtRisk {
+			itm = "assignment risk"
+		} else if l.ITM {
+			itm = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%.2f\t%g\t%.2f\t%.2f\t%s\t%g\t%.2f\n",
+			l.Symbol, l.Expiration, l.DTE, l.Type, l.Strike, qty, l.Spot, l.Mark, itm, l.Shares, l.Cash)
+	}
+
+	fmt.Fprintf(w, "\nCASH REQUIRED %.2f\tBUYING POWER %.2f", r.CashRequired, r.BuyingPower)
+	if r.Shortfall > 0 {
+		fmt.Fprintf(w, "\tSHORTFALL %.2f", r.Shortfall)
+	}
+	fmt.Fprintf(w, "\n")
+
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package robinhood
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Greeks are the sensitivities of an option, or the net sensitivities of a
+// position. For a position they are scaled to dollars per unit move, so that
+// Delta is the equivalent number of shares.
+type Greeks struct {
+	Delta, Gamma, Theta, Vega, Rho float64
+}
+
+func (g *Greeks) add(md *MarketData, scale float64) {
+	g.Delta += md.Delta.Float64 * scale
+	g.Gamma += md.Gamma.Float64 * scale
+	g.Theta += md.Theta.Float64 * scale
+	g.Vega += md.Vega.Float64 * scale
+	g.Rho += md.Rho.Float64 * scale
+}
+
+// PositionGreeks are the net greeks of one options position.
+type PositionGreeks struct {
+	Position OptionPostion
+	Greeks
+	// Incomplete is set if any leg had missing greeks, which were counted
+	// as zero.
+	Incomplete bool
+}
+
+// GetPositionGreeks returns the net greeks of each position, weighted by the
+// positions' quantities, leg ratios and contract multipliers, along with
+// their total.
+func (c *Client) GetPositionGreeks(ctx context.Context, ps []OptionPostion) ([]PositionGreeks, Greeks, error) {
+	var total Greeks
+
+	var insts []*OptionInstrument
+	for _, p := range ps {
+		for _, l := range p.Legs {
+			insts = append(insts, &OptionInstrument{URL: l.Option})
+		}
+	}
+	if len(insts) == 0 {
+		return nil, total, nil
+	}
+
+	mds, err := c.MarketData(ctx, insts...)
+	if err != nil {
+		return nil, total, errors.Wrap(err, "could not get market data")
+	}
+	byURL := map[string]*MarketData{}
+	for _, md := range mds {
+		byURL[md.Instrument] = md
+	}
+
+	out := make([]PositionGreeks, len(ps))
+	for i, p := range ps {
+		out[i].Position = p
+
+		qty, err := strconv.ParseFloat(p.Quantity, 64)
+		if err != nil {
+			return nil, total, errors.Wrapf(err, "invalid quantity for %s position", p.Symbol)
+		}
+		mult, err := strconv.ParseFloat(p.TradeValueMultiplier, 64)
+		if err != nil || mult == 0 {
+			mult = 100
+		}
+
+		for _, l := range p.Legs {
+			ratio, err := strconv.ParseFloat(l.RatioQuantity, 64)
+			if err != nil {
+				return nil, total, errors.Wrapf(err, "invalid leg ratio for %s position", p.Symbol)
+			}
+
+			md := byURL[l.Option]
+			if md == nil {
+				out[i].Incomplete = true
+				continue
+			}
+			if !(md.Delta.Valid && md.Gamma.Valid && md.Theta.Valid && md.Vega.Valid && md.Rho.Valid) {
+				out[i].Incomplete = true
+			}
+
+			scale := qty * ratio * mult
+			if l.PositionType == "short" {
+				scale = -scale
+			}
+			out[i].add(md, scale)
+		}
+
+		total.Delta += out[i].Delta
+		total.Gamma += out[i].Gamma
+		total.Theta += out[i].Theta
+		total.Vega += out[i].Vega
+		total.Rho += out[i].Rho
+	}
+
+	return out, total, nil
+}
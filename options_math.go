@@ -0,0 +1,42 @@
+package robinhood
+
+import (
+	"math"
+	"time"
+
+	"astuart.co/go-robinhood/v2/optmath"
+)
+
+// yearDuration is the length of a year used for time to expiration.
+const yearDuration = 365 * 24 * time.Hour
+
+// yearsToExpiration returns the time from now until the 4pm New York close on
+// an expiration date, in years.
+func yearsToExpiration(d Date, now time.Time) float64 {
+	expiry := time.Date(d.Year(), d.Month(), d.Day(), 16, 0, 0, 0, nyLoc())
+	return math.Max(float64(expiry.Sub(now))/float64(yearDuration), 0)
+}
+
+// OptMathInputs returns the inputs for pricing an option with package
+// optmath as of now. The spot price comes from q, the strike, type and
+// expiration from o, and the dividend yield, which Robinhood reports in
+// percent, from f if it is not nil. Options expire at the 4pm New York close
+// of their expiration date. rate is the risk-free rate, e.g. 0.05; the
+// volatility is left for the caller to set, e.g. from
+// MarketData.ImpliedVolatility.
+func OptMathInputs(q Quote, o *OptionInstrument, f *Fundamental, rate float64, now time.Time) optmath.Inputs {
+	in := optmath.Inputs{
+		Type:   optmath.Call,
+		Spot:   q.Price(),
+		Strike: o.StrikePrice,
+		T:      yearsToExpiration(o.ExpirationDate, now),
+		Rate:   rate,
+	}
+	if o.Type == OptionPut {
+		in.Type = optmath.Put
+	}
+	if f != nil {
+		in.Yield = f.DividendYield / 100
+	}
+	return in
+}
@@ -0,0 +1,240 @@
+package robinhood
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"astuart.co/go-robinhood/v2/optmath"
+	"github.com/pkg/errors"
+)
+
+// A PayoffLeg is one option leg of a strategy to analyze.
+type PayoffLeg struct {
+	Instrument *OptionInstrument
+	Side       OrderSide
+	// Quantity is the number of contracts.
+	Quantity float64
+	// Price is the premium per share paid or received on entry.
+	Price float64
+	// Market is the leg's market data, if known. It is used to estimate the
+	// probability of profit.
+	Market *MarketData
+}
+
+// A StockLeg is a position in the underlying held alongside a strategy's
+// options, e.g. the shares behind a covered call.
+type StockLeg struct {
+	Side     OrderSide
+	Quantity float64
+	Price    float64
+}
+
+// PayoffOpts tunes AnalyzePayoff. Zero-valued fields take sensible defaults.
+type PayoffOpts struct {
+	Stock *StockLeg
+	// Multiplier is the number of shares per contract. Defaults to 100.
+	Multiplier float64
+	// Low and High bound the grid of underlying prices, which defaults to
+	// half the lowest strike to one and a half times the highest.
+	Low, High float64
+	// Steps is the number of intervals in the grid. Defaults to 100.
+	Steps int
+	// Spot, Rate and Time are the price of the underlying, the risk-free
+	// rate, and the time the analysis is made, used to estimate the
+	// probability of profit from implied volatility.
+	Spot float64
+	Rate float64
+	Time time.Time
+}
+
+// A PayoffPoint is the profit or loss of a strategy at expiration for one
+// price of the underlying.
+type PayoffPoint struct {
+	Price, PnL float64
+}
+
+// PayoffAnalysis describes a strategy's profit and loss at expiration. All
+// legs are assumed to expire together, so calendar and diagonal spreads are
+// not modelled well.
+type PayoffAnalysis struct {
+	Grid []PayoffPoint
+	// MaxProfit and MaxLoss are the best and worst outcomes. MaxLoss is
+	// negative, and either may be infinite if it is unbounded.
+	MaxProfit, MaxLoss float64
+	// Breakevens are the underlying prices at which the strategy neither
+	// makes nor loses money, in order.
+	Breakevens []float64
+	// ProbabilityOfProfit is Robinhood's chance of profit for a single leg,
+	// or otherwise an estimate from the legs' implied volatility. It is
+	// zero if it could not be estimated.
+	ProbabilityOfProfit float64
+}
+
+// AnalyzePayoff computes the payoff at expiration of a set of option legs and
+// an optional stock leg.
+func AnalyzePayoff(legs []PayoffLeg, opts PayoffOpts) (*PayoffAnalysis, error) {
+	if len(legs) == 0 && opts.Stock == nil {
+		return nil, errors.New("nothing to analyze")
+	}
+	if opts.Multiplier == 0 {
+		opts.Multiplier = 100
+	}
+	if opts.Steps <= 0 {
+		opts.Steps = 100
+	}
+
+	sign := func(s OrderSide) float64 {
+		if s == Sell {
+			return -1
+		}
+		return 1
+	}
+
+	pnl := func(s float64) float64 {
+		var v float64
+		for _, l := range legs {
+			k := l.Instrument.StrikePrice
+			intrinsic := math.Max(s-k, 0)
+			if l.Instrument.Type == OptionPut {
+				intrinsic = math.Max(k-s, 0)
+			}
+			v += sign(l.Side) * l.Quantity * opts.Multiplier * (intrinsic - l.Price)
+		}
+		if st := opts.Stock; st != nil {
+			v += sign(st.Side) * st.Quantity * (s - st.Price)
+		}
+		return v
+	}
+
+	// The payoff is linear between strikes, so its extremes and zeros can
+	// be found exactly from its value at each strike and its slope above
+	// the highest.
+	kinks := []float64{0}
+	var slope float64
+	for _, l := range legs {
+		kinks = append(kinks, l.Instrument.StrikePrice)
+		if l.Instrument.Type == OptionCall {
+			slope += sign(l.Side) * l.Quantity * opts.Multiplier
+		}
+	}
+	if st := opts.Stock; st != nil {
+		kinks = append(kinks, st.Price)
+		slope += sign(st.Side) * st.Quantity
+	}
+	sort.Float64s(kinks)
+
+	a := &PayoffAnalysis{MaxProfit: math.Inf(-1), MaxLoss: math.Inf(1)}
+	for _, k := range kinks {
+		v := pnl(k)
+		a.MaxProfit = math.Max(a.MaxProfit, v)
+		a.MaxLoss = math.Min(a.MaxLoss, v)
+	}
+	switch {
+	case slope > 1e-9:
+		a.MaxProfit = math.Inf(1)
+	case slope < -1e-9:
+		a.MaxLoss = math.Inf(-1)
+	}
+
+	for i, k := range kinks {
+		v := pnl(k)
+		if v == 0 && (len(a.Breakevens) == 0 || a.Breakevens[len(a.Breakevens)-1] != k) {
+			a.Breakevens = append(a.Breakevens, k)
+		}
+		if i+1 < len(kinks) {
+			next := kinks[i+1]
+			if w := pnl(next); v*w < 0 {
+				a.Breakevens = append(a.Breakevens, k+(next-k)*v/(v-w))
+			}
+		} else if v*slope < 0 {
+			a.Breakevens = append(a.Breakevens, k-v/slope)
+		}
+	}
+
+	lo, hi := opts.Low, opts.High
+	if hi <= lo {
+		lo, hi = math.Inf(1), 0
+		for _, k := range kinks[1:] {
+			lo = math.Min(lo, k)
+			hi = math.Max(hi, k)
+		}
+		lo, hi = lo/2, hi*1.5
+	}
+	step := (hi - lo) / float64(opts.Steps)
+	for i := 0; i <= opts.Steps; i++ {
+		s := lo + float64(i)*step
+		a.Grid = append(a.Grid, PayoffPoint{Price: s, PnL: pnl(s)})
+	}
+
+	a.ProbabilityOfProfit = probabilityOfProfit(legs, opts, kinks, a.Breakevens, pnl)
+	return a, nil
+}
+
+// probabilityOfProfit uses Robinhood's chance of profit for a lone option,
+// and otherwise integrates the lognormal distribution implied by the legs'
+// average implied volatility over the ranges of prices that make money.
+func probabilityOfProfit(legs []PayoffLeg, opts PayoffOpts, kinks, breakevens []float64, pnl func(float64) float64) float64 {
+	if len(legs) == 1 && opts.Stock == nil && legs[0].Market != nil {
+		if legs[0].Side == Sell {
+			return legs[0].Market.ChanceOfProfitShort
+		}
+		return legs[0].Market.ChanceOfProfitLong
+	}
+
+	if opts.Spot <= 0 || len(legs) == 0 {
+		return 0
+	}
+
+	var vol float64
+	var n int
+	first := legs[0].Instrument.ExpirationDate
+	for _, l := range legs {
+		if l.Market != nil && l.Market.ImpliedVolatility.Valid {
+			vol += l.Market.ImpliedVolatility.Float64
+			n++
+		}
+		if l.Instrument.ExpirationDate.Before(first.Time) {
+			first = l.Instrument.ExpirationDate
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	in := optmath.Inputs{
+		Spot: opts.Spot,
+		T:    yearsToExpiration(first, now),
+		Rate: opts.Rate,
+		Vol:  vol / float64(n),
+	}
+
+	bounds := append(append([]float64(nil), kinks...), breakevens...)
+	sort.Float64s(bounds)
+	bounds = append(bounds, math.Inf(1))
+
+	var p float64
+	for i := 0; i+1 < len(bounds); i++ {
+		a, b := bounds[i], bounds[i+1]
+		if b <= a {
+			continue
+		}
+		mid := (a + b) / 2
+		if math.IsInf(b, 1) {
+			mid = a + 1
+		}
+		if pnl(mid) <= 0 {
+			continue
+		}
+		if math.IsInf(b, 1) {
+			p += 1 - optmath.ProbBelow(in, a)
+		} else {
+			p += optmath.ProbBelow(in, b) - optmath.ProbBelow(in, a)
+		}
+	}
+	return p
+}
@@ -0,0 +1,58 @@
+package robinhood
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzePayoff(t *testing.T) {
+	asrt := assert.New(t)
+
+	exp := NewDate(2024, 1, 19)
+	call := func(k float64) *OptionInstrument {
+		return &OptionInstrument{Type: OptionCall, StrikePrice: k, ExpirationDate: exp}
+	}
+	put := func(k float64) *OptionInstrument {
+		return &OptionInstrument{Type: OptionPut, StrikePrice: k, ExpirationDate: exp}
+	}
+
+	// Bull call spread.
+	a, err := AnalyzePayoff([]PayoffLeg{
+		{Instrument: call(100), Side: Buy, Quantity: 1, Price: 3},
+		{Instrument: call(110), Side: Sell, Quantity: 1, Price: 1},
+	}, PayoffOpts{})
+	asrt.NoError(err)
+	asrt.InDelta(800, a.MaxProfit, 1e-9)
+	asrt.InDelta(-200, a.MaxLoss, 1e-9)
+	asrt.Equal([]float64{102}, a.Breakevens)
+	asrt.Len(a.Grid, 101)
+	asrt.InDelta(50, a.Grid[0].Price, 1e-9)
+	asrt.InDelta(165, a.Grid[100].Price, 1e-9)
+
+	// Covered call.
+	a, err = AnalyzePayoff([]PayoffLeg{
+		{Instrument: call(110), Side: Sell, Quantity: 1, Price: 2},
+	}, PayoffOpts{Stock: &StockLeg{Side: Buy, Quantity: 100, Price: 100}})
+	asrt.NoError(err)
+	asrt.InDelta(1200, a.MaxProfit, 1e-9)
+	asrt.InDelta(-9800, a.MaxLoss, 1e-9)
+	asrt.Equal([]float64{98}, a.Breakevens)
+
+	// Short strangle, with an IV-based probability of profit.
+	now := time.Date(2023, 12, 20, 16, 0, 0, 0, nyLoc())
+	md := &MarketData{ImpliedVolatility: NullFloat{Float64: 0.2, Valid: true}}
+	a, err = AnalyzePayoff([]PayoffLeg{
+		{Instrument: put(95), Side: Sell, Quantity: 1, Price: 1, Market: md},
+		{Instrument: call(105), Side: Sell, Quantity: 1, Price: 1, Market: md},
+	}, PayoffOpts{Spot: 100, Time: now})
+	asrt.NoError(err)
+	asrt.InDelta(200, a.MaxProfit, 1e-9)
+	asrt.True(math.IsInf(a.MaxLoss, -1))
+	asrt.Len(a.Breakevens, 2)
+	asrt.InDelta(93, a.Breakevens[0], 1e-9)
+	asrt.InDelta(107, a.Breakevens[1], 1e-9)
+	asrt.True(a.ProbabilityOfProfit > 0.5 && a.ProbabilityOfProfit < 1, a.ProbabilityOfProfit)
+}
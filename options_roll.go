@@ -0,0 +1,129 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// A RollTarget selects the options a position is rolled into.
+type RollTarget struct {
+	// Expiration is the expiration of the new legs.
+	Expiration Date
+	// Strike returns the strike of a new leg given the strike of the leg it
+	// replaces. If nil, strikes are kept.
+	Strike func(strike float64) float64
+}
+
+// A Roll closes an options position and opens the same position at a new
+// expiration or strike, as a single order.
+type Roll struct {
+	Position OptionPostion
+	// Spread lists the closing legs followed by the opening legs.
+	Spread   OptionSpread
+	Quantity float64
+	// Price is the net price of the roll per share. A negative price is a
+	// net credit.
+	Price SpreadPrice
+}
+
+// PlanRoll builds the order that rolls pos to the target and prices it from
+// current market data. Nothing is submitted.
+func (c *Client) PlanRoll(ctx context.Context, pos OptionPostion, target RollTarget) (*Roll, error) {
+	closing, qty, err := c.ClosingSpread(ctx, pos)
+	if err != nil {
+		return nil, err
+	}
+	if len(closing.Legs) == 0 {
+		return nil, errors.New("position has no legs")
+	}
+
+	chain := &OptionChain{ID: closing.Legs[0].Instrument.ChainID, c: c}
+	byType := map[string][]*OptionInstrument{}
+
+	r := &Roll{Position: pos, Quantity: qty, Spread: closing}
+	for _, l := range closing.Legs {
+		strike := l.Instrument.StrikePrice
+		if target.Strike != nil {
+			strike = target.Strike(strike)
+		}
+
+		typ := l.Instrument.Type
+		if _, ok := byType[typ]; !ok {
+			insts, err := chain.GetInstrument(ctx, typ, target.Expiration)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not get %s options for %s", typ, target.Expiration)
+			}
+			byType[typ] = insts
+		}
+
+		var inst *OptionInstrument
+		for _, o := range byType[typ] {
+			if math.Abs(o.StrikePrice-strike) < 1e-6 {
+				inst = o
+				break
+			}
+		}
+		if inst == nil {
+			return nil, fmt.Errorf("no %s %s option at strike %.2f", target.Expiration, typ, strike)
+		}
+
+		r.Spread.Legs = append(r.Spread.Legs, SpreadLeg{
+			Instrument: inst,
+			Side:       opposite(l.Side),
+			Ratio:      l.Ratio,
+			Effect:     EffectOpen,
+		})
+	}
+
+	if err := r.Spread.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.Price, err = c.PriceSpread(ctx, r.Spread)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// String describes the roll's legs and its net credit or debit.
+func (r *Roll) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "roll %s x%g:\n", r.Position.Symbol, r.Quantity)
+	for _, l := range r.Spread.Legs {
+		fmt.Fprintf(&b, "  %s to %s %gx %s %s %.2f\n",
+			strings.ToLower(l.Side.String()), l.Effect, l.ratio(),
+			l.Instrument.ExpirationDate, l.Instrument.Type, l.Instrument.StrikePrice)
+	}
+	fmt.Fprintf(&b, "net %s at mark, %s at natural", netPrice(r.Price.Mark), netPrice(r.Price.Natural))
+	return b.String()
+}
+
+func netPrice(p float64) string {
+	if p < 0 {
+		return fmt.Sprintf("credit %.2f", -p)
+	}
+	return fmt.Sprintf("debit %.2f", p)
+}
+
+// Submit places the roll as a day limit order, as a debit or credit according
+// to the net mark found by PlanRoll. If price is zero, the order is priced at
+// that mark.
+func (r *Roll) Submit(ctx context.Context, c *Client, price float64) (*OptionOrderOutput, error) {
+	if price == 0 {
+		price = math.Round(math.Abs(r.Price.Mark)*100) / 100
+	}
+
+	dir := r.Price.Direction
+	return c.OrderSpread(ctx, r.Spread, SpreadOrderOpts{
+		Quantity:    r.Quantity,
+		Price:       price,
+		Direction:   &dir,
+		TimeInForce: GFD,
+		Type:        Limit,
+	})
+}
@@ -0,0 +1,139 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A ChainRow is one option of a ChainSnapshot with its market data and some
+// figures derived from it.
+type ChainRow struct {
+	Instrument *OptionInstrument
+	// Market is nil if no market data was returned for the option.
+	Market *MarketData
+	DTE    int
+	// Mid is halfway between the bid and ask.
+	Mid float64
+	// Spread is the width of the market, ask less bid.
+	Spread float64
+	// SpreadPct is Spread as a fraction of Mid.
+	SpreadPct float64
+	// Yield is the annualized return of selling the option at Mid: against
+	// the strike for puts, as if cash secured, and against the spot price
+	// for calls, as if covered.
+	Yield float64
+}
+
+// A ChainSnapshot is the market for a set of options at one time.
+type ChainSnapshot struct {
+	Symbol string
+	Spot   float64
+	Time   time.Time
+	Rows   []ChainRow
+}
+
+// GetChainSnapshot fetches market data for every option in the chain and
+// joins it to the instruments, in the order of fc.Instruments.
+func (c *Client) GetChainSnapshot(ctx context.Context, fc *FullChain) (*ChainSnapshot, error) {
+	snap := &ChainSnapshot{Symbol: fc.Chain.Symbol, Spot: fc.Spot, Time: time.Now()}
+
+	if snap.Spot == 0 {
+		qs, err := c.GetQuote(ctx, fc.Chain.Symbol)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not quote underlying")
+		}
+		if len(qs) > 0 {
+			snap.Spot = qs[0].Price()
+		}
+	}
+
+	insts := fc.Instruments()
+	mds, err := c.MarketData(ctx, insts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get market data")
+	}
+	byURL := map[string]*MarketData{}
+	for _, md := range mds {
+		byURL[md.Instrument] = md
+	}
+
+	for _, inst := range insts {
+		r := ChainRow{
+			Instrument: inst,
+			Market:     byURL[inst.URL],
+			DTE:        daysToExpiration(inst.ExpirationDate, snap.Time),
+		}
+		if md := r.Market; md != nil {
+			r.Mid = (md.BidPrice + md.AskPrice) / 2
+			r.Spread = md.AskPrice - md.BidPrice
+			if r.Mid > 0 {
+				r.SpreadPct = r.Spread / r.Mid
+			}
+
+			basis := inst.StrikePrice
+			if inst.Type == OptionCall && snap.Spot > 0 {
+				basis = snap.Spot
+			}
+			if basis > 0 && r.DTE > 0 {
+				r.Yield = r.Mid / basis * 365 / float64(r.DTE)
+			}
+		}
+		snap.Rows = append(snap.Rows, r)
+	}
+
+	return snap, nil
+}
+
+var chainCSVHeader = []string{
+	"symbol", "expiration", "strike", "type", "dte",
+	"bid", "ask", "mark", "mid", "spread", "spread_pct", "last", "volume", "open_interest",
+	"implied_volatility", "delta", "gamma", "theta", "vega", "rho", "yield",
+}
+
+// WriteCSV writes the snapshot as CSV with a header row. Market fields are
+// left empty for options without market data.
+func (s *ChainSnapshot) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(chainCSVHeader); err != nil {
+		return err
+	}
+
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	for _, r := range s.Rows {
+		rec := []string{
+			r.Instrument.ChainSymbol,
+			r.Instrument.ExpirationDate.String(),
+			f(r.Instrument.StrikePrice),
+			r.Instrument.Type,
+			strconv.Itoa(r.DTE),
+		}
+		if md := r.Market; md != nil {
+			rec = append(rec,
+				f(md.BidPrice), f(md.AskPrice), f(md.MarkPrice),
+				f(r.Mid), f(r.Spread), fmt.Sprintf("%.4f", r.SpreadPct),
+				f(md.LastTradePrice), strconv.Itoa(md.Volume), strconv.Itoa(md.OpenInterest),
+				md.ImpliedVolatility.String(), md.Delta.String(), md.Gamma.String(),
+				md.Theta.String(), md.Vega.String(), md.Rho.String(),
+				fmt.Sprintf("%.4f", r.Yield),
+			)
+		} else {
+			rec = append(rec, make([]string, len(chainCSVHeader)-len(rec))...)
+		}
+
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
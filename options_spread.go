@@ -0,0 +1,248 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Position effects of an options leg.
+const (
+	EffectOpen  = "open"
+	EffectClose = "close"
+)
+
+// A SpreadLeg is one leg of an OptionSpread.
+type SpreadLeg struct {
+	Instrument *OptionInstrument
+	Side       OrderSide
+	// Ratio is the number of contracts of this leg per spread. Defaults
+	// to 1.
+	Ratio float64
+	// Effect is EffectOpen or EffectClose.
+	Effect string
+}
+
+func (l SpreadLeg) ratio() float64 {
+	if l.Ratio == 0 {
+		return 1
+	}
+	return l.Ratio
+}
+
+// An OptionSpread is a set of option legs traded together as one order. The
+// helpers below build the common strategies as opening orders; legs may also
+// be listed directly, e.g. to close or roll a position.
+type OptionSpread struct {
+	Legs []SpreadLeg
+}
+
+// VerticalSpread buys long and sells short, which must share an expiration
+// and type.
+func VerticalSpread(long, short *OptionInstrument) OptionSpread {
+	return OptionSpread{Legs: []SpreadLeg{
+		{Instrument: long, Side: Buy, Effect: EffectOpen},
+		{Instrument: short, Side: Sell, Effect: EffectOpen},
+	}}
+}
+
+// CalendarSpread sells near and buys far, which must share a strike and
+// type.
+func CalendarSpread(near, far *OptionInstrument) OptionSpread {
+	return OptionSpread{Legs: []SpreadLeg{
+		{Instrument: near, Side: Sell, Effect: EffectOpen},
+		{Instrument: far, Side: Buy, Effect: EffectOpen},
+	}}
+}
+
+// Straddle buys (or sells) a call and a put at the same strike.
+func Straddle(call, put *OptionInstrument, side OrderSide) OptionSpread {
+	return OptionSpread{Legs: []SpreadLeg{
+		{Instrument: call, Side: side, Effect: EffectOpen},
+		{Instrument: put, Side: side, Effect: EffectOpen},
+	}}
+}
+
+// Strangle buys (or sells) a call and a put at different strikes.
+func Strangle(call, put *OptionInstrument, side OrderSide) OptionSpread {
+	return Straddle(call, put, side)
+}
+
+// Butterfly buys one lower, sells two middle and buys one upper option. With
+// side Sell, every leg is reversed.
+func Butterfly(lower, middle, upper *OptionInstrument, side OrderSide) OptionSpread {
+	return OptionSpread{Legs: []SpreadLeg{
+		{Instrument: lower, Side: side, Effect: EffectOpen, Ratio: 1},
+		{Instrument: middle, Side: opposite(side), Effect: EffectOpen, Ratio: 2},
+		{Instrument: upper, Side: side, Effect: EffectOpen, Ratio: 1},
+	}}
+}
+
+// IronCondor sells a put spread and a call spread: it buys longPut, sells
+// shortPut and shortCall, and buys longCall, strikes ascending.
+func IronCondor(longPut, shortPut, shortCall, longCall *OptionInstrument) OptionSpread {
+	return OptionSpread{Legs: []SpreadLeg{
+		{Instrument: longPut, Side: Buy, Effect: EffectOpen},
+		{Instrument: shortPut, Side: Sell, Effect: EffectOpen},
+		{Instrument: shortCall, Side: Sell, Effect: EffectOpen},
+		{Instrument: longCall, Side: Buy, Effect: EffectOpen},
+	}}
+}
+
+func opposite(s OrderSide) OrderSide {
+	if s == Buy {
+		return Sell
+	}
+	return Buy
+}
+
+// Validate checks that the spread is well formed and that every leg belongs
+// to the same underlying chain.
+func (s OptionSpread) Validate() error {
+	if len(s.Legs) == 0 {
+		return errors.New("spread has no legs")
+	}
+
+	seen := map[string]bool{}
+	for i, l := range s.Legs {
+		if l.Instrument == nil {
+			return fmt.Errorf("leg %d has no instrument", i)
+		}
+		if l.Instrument.ChainID != s.Legs[0].Instrument.ChainID {
+			return fmt.Errorf("leg %d is on chain %s, not %s", i, l.Instrument.ChainSymbol, s.Legs[0].Instrument.ChainSymbol)
+		}
+		if seen[l.Instrument.URL] {
+			return fmt.Errorf("leg %d repeats an option", i)
+		}
+		seen[l.Instrument.URL] = true
+
+		if l.Side != Buy && l.Side != Sell {
+			return fmt.Errorf("leg %d has no side", i)
+		}
+		if l.Effect != EffectOpen && l.Effect != EffectClose {
+			return fmt.Errorf("leg %d has invalid position effect %q", i, l.Effect)
+		}
+		if l.Ratio < 0 {
+			return fmt.Errorf("leg %d has negative ratio", i)
+		}
+	}
+	return nil
+}
+
+// SpreadPrice is the net price of one unit of a spread, per share. Positive
+// values are paid (a debit); negative values are received (a credit).
+type SpreadPrice struct {
+	// Mark is the net of the legs' mark prices.
+	Mark float64
+	// Natural is the net of crossing every leg's market: asks for buys and
+	// bids for sells.
+	Natural float64
+	// Direction is Debit or Credit according to Mark.
+	Direction OptionDirection
+}
+
+// Price computes the net price of the spread from market data for its legs.
+func (s OptionSpread) Price(mds []*MarketData) (SpreadPrice, error) {
+	byURL := map[string]*MarketData{}
+	for _, md := range mds {
+		byURL[md.Instrument] = md
+	}
+
+	var p SpreadPrice
+	for _, l := range s.Legs {
+		md, ok := byURL[l.Instrument.URL]
+		if !ok {
+			return p, fmt.Errorf("no market data for %s", l.Instrument.URL)
+		}
+
+		if l.Side == Buy {
+			p.Mark += l.ratio() * md.MarkPrice
+			p.Natural += l.ratio() * md.AskPrice
+		} else {
+			p.Mark -= l.ratio() * md.MarkPrice
+			p.Natural -= l.ratio() * md.BidPrice
+		}
+	}
+
+	p.Direction = Debit
+	if p.Mark < 0 {
+		p.Direction = Credit
+	}
+	return p, nil
+}
+
+// PriceSpread fetches market data for the spread's legs and computes its net
+// price.
+func (c *Client) PriceSpread(ctx context.Context, s OptionSpread) (SpreadPrice, error) {
+	insts := make([]*OptionInstrument, len(s.Legs))
+	for i, l := range s.Legs {
+		insts[i] = l.Instrument
+	}
+
+	mds, err := c.MarketData(ctx, insts...)
+	if err != nil {
+		return SpreadPrice{}, errors.Wrap(err, "could not get market data")
+	}
+	return s.Price(mds)
+}
+
+// SpreadOrderOpts encapsulates the choices for a spread order.
+type SpreadOrderOpts struct {
+	Quantity float64
+	// Price is the net limit price per share, always positive. Zero prices
+	// the order at the net mark.
+	Price float64
+	// Direction is whether Price is a Debit or a Credit. If nil, it is
+	// worked out from the legs' current mark prices, which for a spread
+	// priced near zero may not match the price intended.
+	Direction   *OptionDirection
+	TimeInForce TimeInForce
+	Type        OrderType
+}
+
+// OrderSpread places a multi-leg options order. Market data for the legs is
+// only fetched if the options leave the price or direction to be worked out.
+// Cancellation of the context cancels only the _http request_.
+func (c *Client) OrderSpread(ctx context.Context, s OptionSpread, o SpreadOrderOpts) (*OptionOrderOutput, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	price, dir := o.Price, o.Direction
+	if price == 0 || dir == nil {
+		p, err := c.PriceSpread(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		if price == 0 {
+			price = math.Round(math.Abs(p.Mark)*100) / 100
+		}
+		if dir == nil {
+			dir = &p.Direction
+		}
+	}
+
+	b := optionInput{
+		Account:     c.Account.URL,
+		Direction:   *dir,
+		TimeInForce: o.TimeInForce,
+		Trigger:     ImmTrigger,
+		Type:        o.Type,
+		Quantity:    o.Quantity,
+		Price:       price,
+		RefID:       uuid.New().String(),
+	}
+	for _, l := range s.Legs {
+		b.Legs = append(b.Legs, Leg{
+			Option:         l.Instrument.URL,
+			PositionEffect: l.Effect,
+			RatioQuantity:  l.ratio(),
+			Side:           l.Side,
+		})
+	}
+
+	return c.submitOptionsOrder(ctx, b)
+}
@@ -2,8 +2,12 @@ package robinhood
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -47,3 +51,95 @@ func TestMarketData(t *testing.T) {
 	spew.Dump(is)
 	fmt.Printf("len(is) = %+v\n", len(is))
 }
+
+func TestMarketDataGreeks(t *testing.T) {
+	asrt := assert.New(t)
+
+	var md MarketData
+	err := json.Unmarshal([]byte(`{
+		"delta": "0.512300",
+		"gamma": 0.0321,
+		"implied_volatility": "",
+		"rho": null,
+		"theta": "-0.043100"
+	}`), &md)
+	asrt.NoError(err)
+
+	asrt.True(md.Delta.Valid)
+	asrt.InDelta(0.5123, md.Delta.Float64, 1e-9)
+	asrt.True(md.Gamma.Valid)
+	asrt.InDelta(0.0321, md.Gamma.Float64, 1e-9)
+	asrt.InDelta(-0.0431, md.Theta.Float64, 1e-9)
+	asrt.False(md.ImpliedVolatility.Valid)
+	asrt.False(md.Rho.Valid)
+	asrt.False(md.Vega.Valid)
+	asrt.Equal("", md.Rho.String())
+
+	bs, err := json.Marshal(md.Rho)
+	asrt.NoError(err)
+	asrt.Equal("null", string(bs))
+}
+
+func TestMarketDataBatches(t *testing.T) {
+	asrt := assert.New(t)
+
+	insts := make([]*OptionInstrument, 70)
+	urls := make([]string, len(insts))
+	for i := range insts {
+		urls[i] = fmt.Sprintf("%sinstruments/%d/", EPOptions, i)
+		insts[i] = &OptionInstrument{URL: urls[i]}
+	}
+	failing := urls[30]
+
+	var mu sync.Mutex
+	var batches [][]string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batch := strings.Split(r.URL.Query().Get("instruments"), ",")
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+
+		for _, u := range batch {
+			if u == failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"detail": "Internal error."}`)
+				return
+			}
+		}
+
+		// Results come back in no particular order.
+		var res []*MarketData
+		for i := len(batch) - 1; i >= 0; i-- {
+			res = append(res, &MarketData{Instrument: batch[i]})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": res})
+	}))
+
+	mds, err := c.MarketData(context.Background(), insts...)
+
+	// Every instrument is asked for exactly once, at most 30 at a time.
+	seen := map[string]int{}
+	for _, b := range batches {
+		asrt.True(len(b) <= 30)
+		for _, u := range b {
+			seen[u]++
+		}
+	}
+	asrt.Len(batches, 3)
+	asrt.Len(seen, len(urls))
+	for _, n := range seen {
+		asrt.Equal(1, n)
+	}
+
+	// Results are in the order asked for, less the failed batch.
+	var got []string
+	for _, md := range mds {
+		got = append(got, md.Instrument)
+	}
+	asrt.Equal(append(append([]string(nil), urls[:30]...), urls[60:]...), got)
+
+	merr, ok := err.(*MarketDataError)
+	if asrt.True(ok, "%T is not a *MarketDataError", err) {
+		asrt.Equal(urls[30:60], merr.Failed)
+	}
+}
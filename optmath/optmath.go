@@ -0,0 +1,204 @@
+// Package optmath prices European options with the Black-Scholes-Merton
+// model and solves for implied volatility.
+//
+// Greeks are expressed the way Robinhood reports them: theta per calendar
+// day, and vega and rho per percentage point of volatility and rate.
+package optmath
+
+import (
+	"errors"
+	"math"
+)
+
+// Type is the type of an option.
+type Type int
+
+// The two types of option.
+const (
+	Call Type = iota
+	Put
+)
+
+// Inputs are the parameters of the pricing model.
+type Inputs struct {
+	Type   Type
+	Spot   float64
+	Strike float64
+	// T is the time to expiration in years.
+	T float64
+	// Rate is the continuously compounded risk-free rate, e.g. 0.05.
+	Rate float64
+	// Yield is the continuous dividend yield, e.g. 0.015.
+	Yield float64
+	// Vol is the annualized volatility, e.g. 0.2.
+	Vol float64
+}
+
+// Greeks are the sensitivities of an option's price.
+type Greeks struct {
+	Delta, Gamma, Theta, Vega, Rho float64
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func (in Inputs) d1d2() (float64, float64) {
+	sd := in.Vol * math.Sqrt(in.T)
+	d1 := (math.Log(in.Spot/in.Strike) + (in.Rate-in.Yield+in.Vol*in.Vol/2)*in.T) / sd
+	return d1, d1 - sd
+}
+
+// degenerate reports whether the option has no time value left to model.
+func (in Inputs) degenerate() bool {
+	return in.T <= 0 || in.Vol <= 0
+}
+
+// Price returns the theoretical price of the option. At or past expiration,
+// or with no volatility, it is the discounted intrinsic value of the forward.
+func (in Inputs) Price() float64 {
+	t := math.Max(in.T, 0)
+	spot := in.Spot * math.Exp(-in.Yield*t)
+	strike := in.Strike * math.Exp(-in.Rate*t)
+
+	if in.degenerate() {
+		if in.Type == Call {
+			return math.Max(spot-strike, 0)
+		}
+		return math.Max(strike-spot, 0)
+	}
+
+	d1, d2 := in.d1d2()
+	if in.Type == Call {
+		return spot*normCDF(d1) - strike*normCDF(d2)
+	}
+	return strike*normCDF(-d2) - spot*normCDF(-d1)
+}
+
+// Greeks returns the option's greeks. At or past expiration, or with no
+// volatility, only delta is non-zero.
+func (in Inputs) Greeks() Greeks {
+	var g Greeks
+	if in.degenerate() {
+		switch {
+		case in.Type == Call && in.Spot > in.Strike:
+			g.Delta = 1
+		case in.Type == Put && in.Spot < in.Strike:
+			g.Delta = -1
+		}
+		return g
+	}
+
+	d1, d2 := in.d1d2()
+	qd := math.Exp(-in.Yield * in.T)
+	rd := math.Exp(-in.Rate * in.T)
+	sqrtT := math.Sqrt(in.T)
+	pdf := normPDF(d1)
+
+	g.Gamma = qd * pdf / (in.Spot * in.Vol * sqrtT)
+	g.Vega = in.Spot * qd * pdf * sqrtT / 100
+
+	decay := -in.Spot * qd * pdf * in.Vol / (2 * sqrtT)
+	if in.Type == Call {
+		g.Delta = qd * normCDF(d1)
+		g.Theta = decay - in.Rate*in.Strike*rd*normCDF(d2) + in.Yield*in.Spot*qd*normCDF(d1)
+		g.Rho = in.Strike * in.T * rd * normCDF(d2) / 100
+	} else {
+		g.Delta = -qd * normCDF(-d1)
+		g.Theta = decay + in.Rate*in.Strike*rd*normCDF(-d2) - in.Yield*in.Spot*qd*normCDF(-d1)
+		g.Rho = -in.Strike * in.T * rd * normCDF(-d2) / 100
+	}
+	g.Theta /= 365
+
+	return g
+}
+
+// ErrNoSolution is returned by ImpliedVol when no volatility reproduces the
+// price, e.g. because it is below the option's intrinsic value.
+var ErrNoSolution = errors.New("optmath: no implied volatility matches price")
+
+// Bounds of the volatilities ImpliedVol searches.
+const (
+	minVol = 1e-6
+	maxVol = 5.0
+)
+
+// ImpliedVol returns the volatility at which the option's theoretical price
+// equals price. in.Vol is used as the first guess if set. Newton's method is
+// tried first and bisection is used if it fails to converge.
+func ImpliedVol(in Inputs, price float64) (float64, error) {
+	const tol = 1e-8
+
+	if in.T <= 0 {
+		return 0, ErrNoSolution
+	}
+
+	v := in.Vol
+	if v <= 0 {
+		v = 0.3
+	}
+
+	at := func(v float64) float64 {
+		in.Vol = v
+		return in.Price() - price
+	}
+
+	lo, hi := at(minVol), at(maxVol)
+	if lo > tol || hi < -tol {
+		return 0, ErrNoSolution
+	}
+
+	for i := 0; i < 50; i++ {
+		diff := at(v)
+		if math.Abs(diff) < tol {
+			return v, nil
+		}
+		vega := in.Greeks().Vega * 100
+		if vega < 1e-10 {
+			break
+		}
+		v -= diff / vega
+		if v < minVol || v > maxVol || math.IsNaN(v) {
+			break
+		}
+	}
+
+	a, b := minVol, maxVol
+	for i := 0; i < 200; i++ {
+		m := (a + b) / 2
+		diff := at(m)
+		if math.Abs(diff) < tol || b-a < 1e-12 {
+			return m, nil
+		}
+		if diff > 0 {
+			b = m
+		} else {
+			a = m
+		}
+	}
+	return (a + b) / 2, nil
+}
+
+// ProbBelow returns the probability that the underlying finishes below price
+// at expiration, assuming it is lognormally distributed with the inputs'
+// volatility and drifts at the risk-free rate less the dividend yield. The
+// option's type and strike are ignored.
+func ProbBelow(in Inputs, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	if in.degenerate() {
+		if in.Spot < price {
+			return 1
+		}
+		return 0
+	}
+
+	sd := in.Vol * math.Sqrt(in.T)
+	mean := math.Log(in.Spot) + (in.Rate-in.Yield-in.Vol*in.Vol/2)*in.T
+	return normCDF((math.Log(price) - mean) / sd)
+}
@@ -0,0 +1,81 @@
+package optmath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrice(t *testing.T) {
+	asrt := assert.New(t)
+
+	// Hull, Options, Futures and Other Derivatives, example 15.6.
+	in := Inputs{Type: Call, Spot: 42, Strike: 40, T: 0.5, Rate: 0.1, Vol: 0.2}
+	asrt.InDelta(4.7594, in.Price(), 1e-4)
+	in.Type = Put
+	asrt.InDelta(0.8086, in.Price(), 1e-4)
+
+	// Put-call parity holds with a dividend yield.
+	c := Inputs{Type: Call, Spot: 100, Strike: 95, T: 0.75, Rate: 0.03, Yield: 0.02, Vol: 0.3}
+	p := c
+	p.Type = Put
+	asrt.InDelta(100*0.985112-95*0.977751, c.Price()-p.Price(), 1e-4)
+
+	// Expired options are worth their intrinsic value.
+	asrt.InDelta(2, Inputs{Type: Call, Spot: 42, Strike: 40}.Price(), 1e-12)
+	asrt.InDelta(0, Inputs{Type: Put, Spot: 42, Strike: 40}.Price(), 1e-12)
+}
+
+func TestGreeks(t *testing.T) {
+	asrt := assert.New(t)
+
+	in := Inputs{Type: Call, Spot: 100, Strike: 100, T: 1, Rate: 0.05, Vol: 0.2}
+	asrt.InDelta(10.4506, in.Price(), 1e-4)
+
+	g := in.Greeks()
+	asrt.InDelta(0.636831, g.Delta, 1e-6)
+	asrt.InDelta(0.018762, g.Gamma, 1e-6)
+	asrt.InDelta(0.375240, g.Vega, 1e-6)
+	asrt.InDelta(-6.414028/365, g.Theta, 1e-6)
+	asrt.InDelta(0.532325, g.Rho, 1e-6)
+
+	in.Type = Put
+	asrt.InDelta(5.5735, in.Price(), 1e-4)
+
+	g = in.Greeks()
+	asrt.InDelta(-0.363169, g.Delta, 1e-6)
+	asrt.InDelta(0.018762, g.Gamma, 1e-6)
+	asrt.InDelta(-1.657880/365, g.Theta, 1e-6)
+	asrt.InDelta(-0.418905, g.Rho, 1e-6)
+}
+
+func TestImpliedVol(t *testing.T) {
+	asrt := assert.New(t)
+
+	for _, vol := range []float64{0.05, 0.2, 0.65, 2.5} {
+		for _, typ := range []Type{Call, Put} {
+			in := Inputs{Type: typ, Spot: 100, Strike: 110, T: 0.25, Rate: 0.04, Yield: 0.01, Vol: vol}
+			price := in.Price()
+
+			in.Vol = 0
+			iv, err := ImpliedVol(in, price)
+			asrt.NoError(err)
+			asrt.InDelta(vol, iv, 1e-5)
+		}
+	}
+
+	// Below intrinsic value.
+	_, err := ImpliedVol(Inputs{Type: Put, Spot: 100, Strike: 110, T: 0.25}, 5)
+	asrt.Equal(ErrNoSolution, err)
+}
+
+func TestProbBelow(t *testing.T) {
+	asrt := assert.New(t)
+
+	in := Inputs{Spot: 100, T: 1, Rate: 0.05, Vol: 0.2}
+	// The risk-neutral probability of finishing above 100 is N(d2) of an
+	// at-the-money option.
+	asrt.InDelta(1-0.559618, ProbBelow(in, 100), 1e-6)
+	asrt.Equal(0.0, ProbBelow(in, 0))
+	asrt.InDelta(1, ProbBelow(in, 1e6), 1e-9)
+}
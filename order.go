@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -64,6 +66,12 @@ type TrailPeg struct {
 	Type string `json:"type,omitempty"`
 }
 
+// DollarAmount is an amount of currency as it appears in order payloads.
+type DollarAmount struct {
+	Amount       float64 `json:"amount,string"`
+	CurrencyCode string  `json:"currency_code"`
+}
+
 type RhOrder struct {
 	Account       string    `json:"account,omitempty"`
 	ExtendedHours bool      `json:"extended_hours"`
@@ -79,6 +87,8 @@ type RhOrder struct {
 	StopPrice     float64   `json:"stop_price,string,omitempty"`
 	TrailingPeg   *TrailPeg `json:"trailing_peg,omitempty"`
 
+	DollarBasedAmount *DollarAmount `json:"dollar_based_amount,omitempty"`
+
 	OverrideDayTradeChecks bool `json:"override_day_trade_checks,omitempty"`
 	OverrideDtbpChecks     bool `json:"override_dtbp_checks,omitempty"`
 
@@ -97,13 +107,35 @@ func (c *Client) CreateOrder(i *Instrument) *RhOrder {
 		Type:             strings.ToLower(Market.String()),
 		Trigger:          ImmTrigger,
 		MarketHours: 	 "regular_hours",
+		RefID:            uuid.New().String(),
 	}
 	return &newOrd
 }
 
+// CreateDollarOrder returns a market buy for the given dollar amount of a
+// fractionally tradable instrument. The share quantity is derived from price,
+// which should be the current ask.
+func (c *Client) CreateDollarOrder(i *Instrument, amount, price float64) *RhOrder {
+	ord := c.CreateOrder(i)
+	ord.Side = strings.ToLower(Buy.String())
+	ord.Price = price
+	ord.Quantity = math.Floor(amount/price*1e6) / 1e6
+	ord.DollarBasedAmount = &DollarAmount{
+		Amount:       math.Round(amount*100) / 100,
+		CurrencyCode: "USD",
+	}
+	return ord
+}
+
 // Order places an order for a given instrument. Cancellation of the given
 // context cancels only the _http request_ and not any orders that may have
 // been created regardless of the cancellation.
+//
+// Every order is given a RefID if it does not have one, and the RefID is
+// recorded in the client's RefJournal, if any, before the order is sent. If
+// the outcome of the request is unknown (e.g. a timeout or a dropped
+// connection), recent orders are searched for the RefID, and the request is
+// retried only if no order was created, so an order is never placed twice.
 func (c *Client) SubmitOrder(ctx context.Context, rhOrd *RhOrder) (*OrderOutput, error) {
 
 	// no fractional pennies.
@@ -112,12 +144,76 @@ func (c *Client) SubmitOrder(ctx context.Context, rhOrd *RhOrder) (*OrderOutput,
 	rhOrd.Side = strings.ToLower(rhOrd.Side)
 	rhOrd.Type = strings.ToLower(rhOrd.Type)
 	rhOrd.TimeInForce = strings.ToLower(rhOrd.TimeInForce)
+	if rhOrd.RefID == "" {
+		rhOrd.RefID = uuid.New().String()
+	}
 
 	bs, err := json.Marshal(rhOrd)
 	if err != nil {
 		return nil, err
 	}
 
+	out, err := c.sendOrder(ctx, rhOrd, bs)
+	c.journal(ctx, JournalEntry{
+		Action:  JournalSubmit,
+		Asset:   AssetEquity,
+		URL:     EPOrders,
+		RefID:   rhOrd.RefID,
+		Request: bs,
+	}, out, err)
+	return out, err
+}
+
+// sendOrder posts the encoded order, reconciling ambiguous failures as
+// described on SubmitOrder.
+func (c *Client) sendOrder(ctx context.Context, rhOrd *RhOrder, bs []byte) (*OrderOutput, error) {
+	if c.DryRun {
+		c.logDryRun("POST", EPOrders, bs)
+		return dryRunOrder(rhOrd), nil
+	}
+
+	if c.RefJournal != nil {
+		if err := c.RefJournal.Begin(rhOrd.RefID, bs); err != nil {
+			return nil, errors.Wrap(err, "could not journal order")
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		out, err := c.postOrder(ctx, bs)
+		if err == nil {
+			c.resolveRef(rhOrd.RefID, out.ID)
+			return out, nil
+		}
+		if !isAmbiguous(err) {
+			c.resolveRef(rhOrd.RefID, "")
+			return out, err
+		}
+
+		found, ferr := c.FindOrderByRefID(ctx, rhOrd.RefID)
+		if ferr != nil {
+			return out, errors.Wrapf(err, "order %s may have been created", rhOrd.RefID)
+		}
+		if found != nil {
+			c.resolveRef(rhOrd.RefID, found.ID)
+			return found, nil
+		}
+		if attempt >= submitRetries || ctx.Err() != nil {
+			return out, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return out, err
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		}
+	}
+}
+
+// submitRetries is how many times SubmitOrder resends an order that was
+// confirmed not to have been created after an ambiguous failure.
+const submitRetries = 2
+
+func (c *Client) postOrder(ctx context.Context, bs []byte) (*OrderOutput, error) {
 	post, err := http.NewRequest("POST", EPOrders, bytes.NewReader(bs))
 	if err != nil {
 		return nil, fmt.Errorf("error creating POST http.Request: %w", err)
@@ -134,43 +230,65 @@ func (c *Client) SubmitOrder(ctx context.Context, rhOrd *RhOrder) (*OrderOutput,
 	return &out, nil
 }
 
+// isAmbiguous reports whether an order may have been created despite err.
+// Only an error message from the API is a definite rejection.
+func isAmbiguous(err error) bool {
+	_, ok := err.(ErrorMap)
+	return !ok
+}
+
+// FindOrderByRefID searches recent orders for one with the given ref ID,
+// returning nil if there is none. If ctx is already done, a fresh context is
+// used so that an order whose submission timed out can still be found.
+func (c *Client) FindOrderByRefID(ctx context.Context, refID string) (*OrderOutput, error) {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+	}
+
+	recent, err := c.RecentOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range recent {
+		if recent[i].RefID == refID {
+			return &recent[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // OrderOutput is the response from the Order api
 type OrderOutput struct {
-	ID                 string    `json:"id"`
-	RefID              string    `json:"ref_id"`
-	URL                string    `json:"url"`
-	Account            string    `json:"account"`
-	Position           string    `json:"position"`
-	CancelURL          string    `json:"cancel"`
-	Instrument         string    `json:"instrument"`
-	CumulativeQuantity float64   `json:"cumulative_quantity,string"`
-	AveragePrice       float64   `json:"average_price,string"`
-	Fees               float64   `json:"fees,string"`
-	State              string    `json:"state"`
-	Type               string    `json:"type"`
-	Side               string    `json:"side"`
-	TimeInForce        string    `json:"time_in_force"`
-	Trigger            string    `json:"trigger"`
-	Price              float64   `json:"price,string"`
-	StopPrice          float64   `json:"stop_price,string"`
-	Quantity           float64   `json:"quantity,string"`
-	RejectReason       string    `json:"reject_reason"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	LastTransactionAt  time.Time `json:"last_transaction_at"`
-	Executions         []struct {
-		Price                  float64   `json:"price,string"`
-		Quantity               float64   `json:"quantity,string"`
-		SettlementDate         string    `json:"settlement_date"`
-		Timestamp              time.Time `json:"timestamp"`
-		ID                     string    `json:"id"`
-		IpoAccessExecutionRank float64   `json:"ipo_access_execution_rank"`
-	} `json:"executions"`
-	ExtendedHours          bool      `json:"extended_hours"`
-	OverrideDtbpChecks     bool      `json:"override_dtbp_checks"`
-	OverrideDayTradeChecks bool      `json:"override_day_trade_checks"`
-	ResponseCategory       string    `json:"response_category"`
-	StopTriggeredAt        time.Time `json:"stop_triggered_at"`
+	ID                     string      `json:"id"`
+	RefID                  string      `json:"ref_id"`
+	URL                    string      `json:"url"`
+	Account                string      `json:"account"`
+	Position               string      `json:"position"`
+	CancelURL              string      `json:"cancel"`
+	Instrument             string      `json:"instrument"`
+	CumulativeQuantity     float64     `json:"cumulative_quantity,string"`
+	AveragePrice           float64     `json:"average_price,string"`
+	Fees                   float64     `json:"fees,string"`
+	State                  string      `json:"state"`
+	Type                   string      `json:"type"`
+	Side                   string      `json:"side"`
+	TimeInForce            string      `json:"time_in_force"`
+	Trigger                string      `json:"trigger"`
+	Price                  float64     `json:"price,string"`
+	StopPrice              float64     `json:"stop_price,string"`
+	Quantity               float64     `json:"quantity,string"`
+	RejectReason           string      `json:"reject_reason"`
+	CreatedAt              time.Time   `json:"created_at"`
+	UpdatedAt              time.Time   `json:"updated_at"`
+	LastTransactionAt      time.Time   `json:"last_transaction_at"`
+	Executions             []Execution `json:"executions"`
+	ExtendedHours          bool        `json:"extended_hours"`
+	OverrideDtbpChecks     bool        `json:"override_dtbp_checks"`
+	OverrideDayTradeChecks bool        `json:"override_day_trade_checks"`
+	ResponseCategory       string      `json:"response_category"`
+	StopTriggeredAt        time.Time   `json:"stop_triggered_at"`
 	TrailingPeg            struct {
 		Type       string `json:"type"`
 		Percentage int    `json:"percentage"`
@@ -210,13 +328,71 @@ type OrderOutput struct {
 	IsIpoAccessPriceFinalized   bool    `json:"is_ipo_access_price_finalized"`
 }
 
+// Order states reported by the API.
+const (
+	OrderStateQueued          = "queued"
+	OrderStateUnconfirmed     = "unconfirmed"
+	OrderStateConfirmed       = "confirmed"
+	OrderStatePartiallyFilled = "partially_filled"
+	OrderStateFilled          = "filled"
+	OrderStateCancelled       = "cancelled"
+	OrderStateRejected        = "rejected"
+	OrderStateFailed          = "failed"
+)
+
+// isDoneState reports whether an order in the given state can no longer be
+// filled. The crypto API spells cancellation "canceled".
+func isDoneState(state string) bool {
+	switch state {
+	case OrderStateFilled, OrderStateCancelled, "canceled",
+		OrderStateRejected, OrderStateFailed, OrderStateDryRun:
+		return true
+	}
+	return false
+}
+
+// IsDone reports whether the order has reached a final state.
+func (o OrderOutput) IsDone() bool {
+	return isDoneState(o.State)
+}
+
+// Wait calls Update every interval until the order reaches a final state or
+// the context is cancelled.
+func (o *OrderOutput) Wait(ctx context.Context, client *Client, interval time.Duration) error {
+	for !o.IsDone() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if err := o.Update(ctx, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Update returns any errors and updates the item with any recent changes.
+// Synthetic dry-run orders have nothing to fetch and are left unchanged.
 func (o *OrderOutput) Update(ctx context.Context, client *Client) error {
+	if o.State == OrderStateDryRun {
+		return nil
+	}
 	return client.GetAndDecode(ctx, o.URL, o)
 }
 
 // Cancel attempts to cancel an odrer
-func (o OrderOutput) Cancel(ctx context.Context, client *Client) error {
+func (o OrderOutput) Cancel(ctx context.Context, client *Client) (err error) {
+	defer func() {
+		client.journalCancel(ctx, AssetEquity, o.ID, o.CancelURL, err)
+	}()
+
+	if client.DryRun {
+		client.logDryRun("POST", o.CancelURL, nil)
+		return nil
+	}
+
 	post, err := http.NewRequest("POST", o.CancelURL, nil)
 	if err != nil {
 		return err
@@ -234,9 +410,17 @@ func (o OrderOutput) Cancel(ctx context.Context, client *Client) error {
 	return nil
 }
 
-func (c *Client) CancelOrderById(ctx context.Context, id string) error {
+func (c *Client) CancelOrderById(ctx context.Context, id string) (err error) {
 
 	var ordUrl = EPOrders + id + "/cancel/"
+	defer func() {
+		c.journalCancel(ctx, AssetEquity, id, ordUrl, err)
+	}()
+
+	if c.DryRun {
+		c.logDryRun("POST", ordUrl, nil)
+		return nil
+	}
 
 	post, err := http.NewRequest("POST", ordUrl, nil)
 	if err != nil {
@@ -255,6 +439,16 @@ func (c *Client) CancelOrderById(ctx context.Context, id string) error {
 	return nil
 }
 
+// GetOrder returns the order with the given ID.
+func (c *Client) GetOrder(ctx context.Context, id string) (*OrderOutput, error) {
+	var o OrderOutput
+	err := c.GetAndDecode(ctx, EPOrders+id+"/", &o)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
 // RecentOrders returns any recent orders made by this client.
 func (c *Client) RecentOrders(ctx context.Context) ([]OrderOutput, error) {
 	var o struct {
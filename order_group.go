@@ -0,0 +1,525 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// Kinds of OrderGroup.
+const (
+	GroupBracket = "bracket"
+	GroupOCO     = "oco"
+)
+
+// States of an OrderGroup.
+const (
+	// GroupPendingEntry means a bracket is waiting for its entry to fill.
+	GroupPendingEntry = "pending_entry"
+	// GroupActive means the exit (or one-cancels-other) legs are working.
+	GroupActive = "active"
+	// GroupDone means every order in the group has reached a final state.
+	GroupDone = "done"
+	// GroupFailed means the group could not be kept consistent and needs
+	// attention; see OrderGroup.Error.
+	GroupFailed = "failed"
+)
+
+// A GroupLeg is one order within an OrderGroup. A leg may be backed by
+// several orders over its life if it is resized with Replace.
+type GroupLeg struct {
+	Order   *RhOrder `json:"order"`
+	OrderID string   `json:"order_id,omitempty"`
+	State   string   `json:"state,omitempty"`
+	// Quantity is the size of the order currently backing the leg.
+	Quantity float64 `json:"quantity"`
+	// Filled is the quantity filled across every order backing the leg.
+	Filled float64 `json:"filled"`
+	// PriorFilled is the part of Filled from orders since replaced.
+	PriorFilled float64 `json:"prior_filled"`
+	// Emulated legs are held on the client and only submitted once the
+	// price of Order.Symbol reaches Order.StopPrice, which sets Triggered.
+	Emulated  bool `json:"emulated,omitempty"`
+	Triggered bool `json:"triggered,omitempty"`
+}
+
+// open returns the unfilled quantity of the order currently backing the leg.
+func (l *GroupLeg) open() float64 {
+	return l.Quantity - (l.Filled - l.PriorFilled)
+}
+
+// working reports whether the leg has an order that may still fill.
+func (l *GroupLeg) working() bool {
+	return l.OrderID != "" && !isDoneState(l.State)
+}
+
+// pending reports whether the leg is emulated and has yet to be submitted.
+func (l *GroupLeg) pending() bool {
+	return l.Emulated && l.OrderID == "" && !isDoneState(l.State)
+}
+
+// An OrderGroup is a set of orders an OrderGroupManager keeps consistent with
+// one another. Robinhood has no native bracket or one-cancels-other orders,
+// so the link between them exists only on the client.
+type OrderGroup struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	State string `json:"state"`
+	// Size is the quantity the legs together may close: the filled
+	// quantity of a bracket's entry, or the size of an OCO leg.
+	Size      float64     `json:"size"`
+	Entry     *GroupLeg   `json:"entry,omitempty"`
+	Legs      []*GroupLeg `json:"legs"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// clone returns a copy of the group that shares no mutable state with it.
+func (g *OrderGroup) clone() *OrderGroup {
+	out := *g
+	if g.Entry != nil {
+		e := *g.Entry
+		out.Entry = &e
+	}
+	out.Legs = make([]*GroupLeg, len(g.Legs))
+	for i, l := range g.Legs {
+		l := *l
+		out.Legs[i] = &l
+	}
+	return &out
+}
+
+// An OrderGroupManager emulates bracket and one-cancels-other orders on top
+// of SubmitOrder by polling the orders it submits and reacting to fills. If
+// Path is set, the state of every group is saved there after each change and
+// a manager created with the same path resumes where the last one stopped.
+//
+// Robinhood holds shares for every working sell order, so the API rejects a
+// second exit for the same shares. Groups therefore keep only their limit
+// and market orders working and watch the price for their stops themselves;
+// see Bracket and OCO. If an order cannot be submitted, the orders of the group already
+// working are cancelled and the failure is recorded on the group rather than
+// retried.
+type OrderGroupManager struct {
+	Client       *Client
+	Path         string
+	PollInterval time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*OrderGroup
+}
+
+// NewOrderGroupManager returns a manager for the client, restoring any groups
+// previously saved at path. An empty path keeps state in memory only.
+func NewOrderGroupManager(c *Client, path string) (*OrderGroupManager, error) {
+	m := &OrderGroupManager{
+		Client:       c,
+		Path:         path,
+		PollInterval: 5 * time.Second,
+		groups:       map[string]*OrderGroup{},
+	}
+
+	if path == "" {
+		return m, nil
+	}
+
+	var gs []*OrderGroup
+	if err := loadJSON(path, &gs); err != nil {
+		return nil, errors.Wrap(err, "could not load order groups")
+	}
+	for _, g := range gs {
+		m.groups[g.ID] = g
+	}
+	return m, nil
+}
+
+// Groups returns a snapshot of every group known to the manager, oldest
+// first.
+func (m *OrderGroupManager) Groups() []*OrderGroup {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]*OrderGroup, 0, len(m.groups))
+	for _, g := range m.sorted() {
+		out = append(out, g.clone())
+	}
+	return out
+}
+
+// Bracket submits entry and, once it is done filling, submits takeProfit for
+// the filled quantity. stopLoss is emulated: when a poll finds the price of
+// its symbol at or through its StopPrice, takeProfit is cancelled and
+// stopLoss is submitted for whatever takeProfit did not fill. The stop is
+// therefore only as timely as PollInterval, and is not watched at all while
+// no manager is running.
+func (m *OrderGroupManager) Bracket(ctx context.Context, entry, takeProfit, stopLoss *RhOrder) (*OrderGroup, error) {
+	if stopLoss.StopPrice <= 0 {
+		return nil, errors.New("stop loss has no stop price")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.newGroup(GroupBracket)
+	g.State = GroupPendingEntry
+	g.Entry = &GroupLeg{Order: entry}
+	g.Legs = []*GroupLeg{{Order: takeProfit}, {Order: stopLoss, Emulated: true}}
+
+	if err := m.submit(ctx, g.Entry, entry.Quantity); err != nil {
+		m.fail(g, errors.Wrap(err, "could not submit entry"))
+		return g.clone(), m.saveWith(err)
+	}
+	return g.clone(), m.save()
+}
+
+// OCO cancels whichever of two orders remains when the other fills, and
+// shrinks it to match partial fills. Both orders are expected to be for the
+// same quantity.
+//
+// Stop orders are emulated as in Bracket: they are held until a poll finds
+// their price reached, when the other order is cancelled and the stop is
+// submitted for whatever remains. Other orders are submitted at once, so two
+// of them may not both sell the same symbol.
+func (m *OrderGroupManager) OCO(ctx context.Context, a, b *RhOrder) (*OrderGroup, error) {
+	legs := []*GroupLeg{{Order: a}, {Order: b}}
+	var sells []string
+	for _, l := range legs {
+		if l.Order.Trigger == StopTrigger {
+			if l.Order.StopPrice <= 0 {
+				return nil, errors.New("stop order has no stop price")
+			}
+			l.Emulated = true
+			continue
+		}
+		if strings.EqualFold(l.Order.Side, Sell.String()) {
+			sells = append(sells, strings.ToUpper(l.Order.Symbol))
+		}
+	}
+	if len(sells) == 2 && sells[0] == sells[1] {
+		return nil, fmt.Errorf("both orders sell %s, which Robinhood would reject", sells[0])
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.newGroup(GroupOCO)
+	g.State = GroupActive
+	g.Size = a.Quantity
+	g.Legs = legs
+
+	for _, l := range g.Legs {
+		if l.Emulated {
+			continue
+		}
+		if err := m.submit(ctx, l, l.Order.Quantity); err != nil {
+			m.fail(g, errors.Wrap(err, "could not submit leg"))
+			m.cancelWorking(ctx, g)
+			return g.clone(), m.saveWith(err)
+		}
+	}
+	return g.clone(), m.save()
+}
+
+// Cancel cancels every working order in the group and marks it done.
+func (m *OrderGroupManager) Cancel(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.groups[id]
+	if !ok {
+		return fmt.Errorf("no order group %q", id)
+	}
+
+	err := m.cancelWorking(ctx, g)
+	if err == nil {
+		g.State = GroupDone
+		g.UpdatedAt = time.Now()
+	}
+	return m.saveWith(err)
+}
+
+// Run calls Poll every PollInterval until the context is cancelled. Errors
+// from individual polls are logged through the client and do not stop the
+// loop.
+func (m *OrderGroupManager) Run(ctx context.Context) error {
+	for {
+		if err := m.Poll(ctx); err != nil {
+			m.Client.logf("robinhood: order groups: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.PollInterval):
+		}
+	}
+}
+
+// Poll refreshes every unfinished group once, submitting, resizing or
+// cancelling orders as their siblings fill.
+func (m *OrderGroupManager) Poll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs error
+	for _, g := range m.sorted() {
+		if g.State == GroupDone || g.State == GroupFailed {
+			continue
+		}
+		if err := m.advance(ctx, g); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "group %s", g.ID))
+		}
+		g.UpdatedAt = time.Now()
+	}
+	return m.saveWith(errs)
+}
+
+func (m *OrderGroupManager) advance(ctx context.Context, g *OrderGroup) error {
+	if g.State == GroupPendingEntry {
+		if err := m.refresh(ctx, g.Entry); err != nil {
+			return err
+		}
+		if !isDoneState(g.Entry.State) {
+			return nil
+		}
+		if g.Entry.Filled <= 0 {
+			g.State = GroupDone
+			return nil
+		}
+
+		g.Size = g.Entry.Filled
+		for _, l := range g.Legs {
+			if l.Emulated {
+				continue
+			}
+			if err := m.submit(ctx, l, g.Size); err != nil {
+				m.fail(g, errors.Wrap(err, "could not submit exit"))
+				if cerr := m.cancelWorking(ctx, g); cerr != nil {
+					err = multierror.Append(err, cerr)
+				}
+				return err
+			}
+		}
+		g.State = GroupActive
+		return nil
+	}
+
+	for _, l := range g.Legs {
+		if err := m.refresh(ctx, l); err != nil {
+			return err
+		}
+	}
+
+	// Whatever one leg fills, the others no longer need to close.
+	remaining := g.Size
+	for _, l := range g.Legs {
+		remaining -= l.Filled
+	}
+
+	var errs error
+	triggered := false
+	for _, l := range g.Legs {
+		if !l.pending() {
+			continue
+		}
+		if remaining <= 0 {
+			l.State = OrderStateCancelled
+			continue
+		}
+		if !l.Triggered {
+			hit, err := m.stopReached(ctx, l.Order)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			l.Triggered = hit
+		}
+		triggered = triggered || l.Triggered
+	}
+
+	// A triggered stop closes the whole position, so the working legs are
+	// cancelled to free the shares they hold.
+	open := remaining
+	if triggered {
+		open = 0
+	}
+	working := false
+	for _, l := range g.Legs {
+		if l.working() && l.open() > open {
+			if err := m.resize(ctx, l, open); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
+		working = working || l.working()
+	}
+
+	if triggered && !working {
+		// The other legs were seen to be done by the refresh above, so
+		// remaining counts every fill they made.
+		for _, l := range g.Legs {
+			if !l.pending() || !l.Triggered {
+				continue
+			}
+			if err := m.submit(ctx, l, remaining); err != nil {
+				m.fail(g, errors.Wrap(err, "could not submit stop loss"))
+				return err
+			}
+			break
+		}
+	}
+
+	done := true
+	for _, l := range g.Legs {
+		done = done && !l.working() && !l.pending()
+	}
+	if done {
+		g.State = GroupDone
+	}
+	return errs
+}
+
+// resize cancels the leg's order, or replaces it with one for the given open
+// quantity.
+func (m *OrderGroupManager) resize(ctx context.Context, l *GroupLeg, open float64) error {
+	o, err := m.Client.GetOrder(ctx, l.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if open <= 0 {
+		if err := o.Cancel(ctx, m.Client); err != nil {
+			return errors.Wrapf(err, "could not cancel order %s", o.ID)
+		}
+		return nil
+	}
+
+	orig, repl, err := o.Replace(ctx, m.Client, OrderChanges{Quantity: o.CumulativeQuantity + open})
+	l.Filled = l.PriorFilled + orig.CumulativeQuantity
+	l.State = orig.State
+	if err == ErrOrderFilled {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not resize order %s", o.ID)
+	}
+
+	l.PriorFilled = l.Filled
+	l.OrderID = repl.ID
+	l.State = repl.State
+	l.Quantity = repl.Quantity
+	return nil
+}
+
+// stopReached reports whether the price of the order's symbol has reached its
+// stop price, falling for a sell and rising for a buy.
+func (m *OrderGroupManager) stopReached(ctx context.Context, o *RhOrder) (bool, error) {
+	qs, err := m.Client.GetQuote(ctx, o.Symbol)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not get quote for %s", o.Symbol)
+	}
+	if len(qs) == 0 {
+		return false, fmt.Errorf("no quote for %s", o.Symbol)
+	}
+
+	price := qs[0].Price()
+	if strings.EqualFold(o.Side, Buy.String()) {
+		return price >= o.StopPrice, nil
+	}
+	return price <= o.StopPrice, nil
+}
+
+func (m *OrderGroupManager) submit(ctx context.Context, l *GroupLeg, qty float64) error {
+	l.Order.Quantity = qty
+	out, err := m.Client.SubmitOrder(ctx, l.Order)
+	if err != nil {
+		return err
+	}
+	l.OrderID = out.ID
+	l.State = out.State
+	l.Quantity = out.Quantity
+	return nil
+}
+
+func (m *OrderGroupManager) refresh(ctx context.Context, l *GroupLeg) error {
+	if !l.working() {
+		return nil
+	}
+	o, err := m.Client.GetOrder(ctx, l.OrderID)
+	if err != nil {
+		return err
+	}
+	l.State = o.State
+	l.Filled = l.PriorFilled + o.CumulativeQuantity
+	return nil
+}
+
+func (m *OrderGroupManager) cancelWorking(ctx context.Context, g *OrderGroup) error {
+	legs := g.Legs
+	if g.Entry != nil {
+		legs = append([]*GroupLeg{g.Entry}, legs...)
+	}
+
+	var errs error
+	for _, l := range legs {
+		if !l.working() {
+			continue
+		}
+		if err := m.Client.CancelOrderById(ctx, l.OrderID); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "could not cancel order %s", l.OrderID))
+		}
+	}
+	return errs
+}
+
+func (m *OrderGroupManager) newGroup(kind string) *OrderGroup {
+	now := time.Now()
+	g := &OrderGroup{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.groups[g.ID] = g
+	return g
+}
+
+func (m *OrderGroupManager) fail(g *OrderGroup, err error) {
+	g.State = GroupFailed
+	g.Error = err.Error()
+	g.UpdatedAt = time.Now()
+}
+
+func (m *OrderGroupManager) sorted() []*OrderGroup {
+	gs := make([]*OrderGroup, 0, len(m.groups))
+	for _, g := range m.groups {
+		gs = append(gs, g)
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		return gs[i].CreatedAt.Before(gs[j].CreatedAt)
+	})
+	return gs
+}
+
+func (m *OrderGroupManager) save() error {
+	if m.Path == "" {
+		return nil
+	}
+	return saveJSON(m.Path, m.sorted())
+}
+
+// saveWith saves the manager's state and returns err, or the save error if
+// err is nil.
+func (m *OrderGroupManager) saveWith(err error) error {
+	if serr := m.save(); serr != nil && err == nil {
+		return errors.Wrap(serr, "could not save order groups")
+	}
+	return err
+}
@@ -0,0 +1,287 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOrders serves the order and quote endpoints from memory.
+type fakeOrders struct {
+	mu     sync.Mutex
+	orders map[string]*OrderOutput
+	// posted holds every order submitted, in order, and cancelled the IDs
+	// of every order cancelled.
+	posted    []RhOrder
+	cancelled []string
+	// reject, if non-zero, is the 1-based index of a POST to reject, and
+	// drop of one whose connection is closed without a response.
+	// dropCreated says whether the dropped order is created regardless.
+	reject      int
+	drop        int
+	dropCreated bool
+	price       float64
+}
+
+func newFakeOrders() *fakeOrders {
+	return &fakeOrders{orders: map[string]*OrderOutput{}}
+}
+
+func (f *fakeOrders) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/orders/")
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/instruments/"):
+		json.NewEncoder(w).Encode(Instrument{Symbol: "ABC"})
+
+	case r.URL.Path == "/quotes/":
+		q := Quote{Symbol: r.URL.Query().Get("symbols"), LastTradePrice: f.price, LastExtendedHoursTradePrice: f.price}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": []Quote{q}})
+
+	case r.Method == "POST" && path == "":
+		var o RhOrder
+		json.NewDecoder(r.Body).Decode(&o)
+		f.posted = append(f.posted, o)
+		if len(f.posted) == f.reject {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"detail": "Not enough shares to sell."}`)
+			return
+		}
+
+		dropped := len(f.posted) == f.drop
+		if dropped && !f.dropCreated {
+			hangUp(w)
+			return
+		}
+
+		id := fmt.Sprintf("order-%d", len(f.posted))
+		out := &OrderOutput{
+			ID:        id,
+			RefID:     o.RefID,
+			URL:       EPOrders + id + "/",
+			CancelURL: EPOrders + id + "/cancel/",
+			State:     OrderStateConfirmed,
+			Quantity:  o.Quantity,
+			// Replace resubmits from these.
+			Instrument: EPInstruments + "abc/",
+			Side:       o.Side,
+			Type:       o.Type,
+			Trigger:    o.Trigger,
+			Price:      o.Price,
+			StopPrice:  o.StopPrice,
+		}
+		f.orders[id] = out
+		if dropped {
+			hangUp(w)
+			return
+		}
+		json.NewEncoder(w).Encode(out)
+
+	case r.Method == "GET" && path == "":
+		var res []*OrderOutput
+		for i := range f.posted {
+			if o, ok := f.orders[fmt.Sprintf("order-%d", i+1)]; ok {
+				res = append(res, o)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": res})
+
+	case r.Method == "POST" && strings.HasSuffix(path, "/cancel/"):
+		id := strings.TrimSuffix(path, "/cancel/")
+		f.cancelled = append(f.cancelled, id)
+		f.orders[id].State = OrderStateCancelled
+		fmt.Fprint(w, `{}`)
+
+	default:
+		o, ok := f.orders[strings.TrimSuffix(path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(o)
+	}
+}
+
+// hangUp closes the connection behind w without responding.
+func hangUp(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// fill fills qty more of the order, finishing it once it is fully filled.
+func (f *fakeOrders) fill(id string, qty float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o := f.orders[id]
+	o.CumulativeQuantity += qty
+	o.State = OrderStatePartiallyFilled
+	if o.CumulativeQuantity >= o.Quantity {
+		o.State = OrderStateFilled
+	}
+}
+
+func (f *fakeOrders) setPrice(p float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.price = p
+}
+
+func TestBracketEmulatesStopLoss(t *testing.T) {
+	asrt := assert.New(t)
+	ctx := context.Background()
+
+	f := newFakeOrders()
+	m, err := NewOrderGroupManager(newTestClient(t, f), "")
+	asrt.NoError(err)
+
+	g, err := m.Bracket(ctx,
+		&RhOrder{Symbol: "ABC", Side: "buy", Type: "market", Quantity: 10},
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "limit", Price: 110},
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "market", Trigger: StopTrigger, StopPrice: 90},
+	)
+	asrt.NoError(err)
+	asrt.Equal(GroupPendingEntry, g.State)
+
+	// Only the take profit is placed when the entry fills.
+	f.fill("order-1", 10)
+	f.setPrice(100)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Len(f.posted, 2)
+	asrt.Equal(110.0, f.posted[1].Price)
+	asrt.InDelta(10, f.posted[1].Quantity, 1e-9)
+	asrt.Equal(GroupActive, m.Groups()[0].State)
+
+	// Reaching the stop cancels the partly filled take profit...
+	f.fill("order-2", 4)
+	f.setPrice(89)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Equal([]string{"order-2"}, f.cancelled)
+	asrt.Len(f.posted, 2)
+
+	// ...and the stop loss is placed for the rest once it is cancelled.
+	asrt.NoError(m.Poll(ctx))
+	asrt.Len(f.posted, 3)
+	asrt.Equal(StopTrigger, f.posted[2].Trigger)
+	asrt.InDelta(6, f.posted[2].Quantity, 1e-9)
+
+	f.fill("order-3", 6)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Equal(GroupDone, m.Groups()[0].State)
+}
+
+func TestOCOEmulatesStop(t *testing.T) {
+	asrt := assert.New(t)
+	ctx := context.Background()
+
+	f := newFakeOrders()
+	m, err := NewOrderGroupManager(newTestClient(t, f), "")
+	asrt.NoError(err)
+
+	f.setPrice(100)
+	g, err := m.OCO(ctx,
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "limit", Price: 110, Quantity: 10},
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "market", Trigger: StopTrigger, StopPrice: 90, Quantity: 10},
+	)
+	asrt.NoError(err)
+	asrt.Equal(GroupActive, g.State)
+	asrt.Len(f.posted, 1)
+
+	f.fill("order-1", 4)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Len(f.posted, 1)
+
+	// Once the limit fills, the stop is dropped without ever being placed.
+	f.fill("order-1", 6)
+	asrt.NoError(m.Poll(ctx))
+	g = m.Groups()[0]
+	asrt.Equal(GroupDone, g.State)
+	asrt.Equal(OrderStateCancelled, g.Legs[1].State)
+	asrt.Empty(g.Legs[1].OrderID)
+	asrt.Len(f.posted, 1)
+	asrt.Empty(f.cancelled)
+}
+
+func TestOCOShrinksOtherLeg(t *testing.T) {
+	asrt := assert.New(t)
+	ctx := context.Background()
+
+	defer func(d time.Duration) { ReplacePollInterval = d }(ReplacePollInterval)
+	ReplacePollInterval = time.Millisecond
+
+	f := newFakeOrders()
+	m, err := NewOrderGroupManager(newTestClient(t, f), "")
+	asrt.NoError(err)
+
+	_, err = m.OCO(ctx,
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "limit", Price: 110, Quantity: 10},
+		&RhOrder{Symbol: "ABC", Side: "buy", Type: "limit", Price: 90, Quantity: 10},
+	)
+	asrt.NoError(err)
+	asrt.Len(f.posted, 2)
+
+	// A partial fill of one leg replaces the other with a smaller order...
+	f.fill("order-1", 4)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Equal([]string{"order-2"}, f.cancelled)
+	asrt.Len(f.posted, 3)
+	asrt.Equal("buy", f.posted[2].Side)
+	asrt.Equal(90.0, f.posted[2].Price)
+	asrt.InDelta(6, f.posted[2].Quantity, 1e-9)
+	asrt.Equal(GroupActive, m.Groups()[0].State)
+
+	// ...and a complete fill cancels it.
+	f.fill("order-1", 6)
+	asrt.NoError(m.Poll(ctx))
+	asrt.Equal([]string{"order-2", "order-3"}, f.cancelled)
+	asrt.Len(f.posted, 3)
+
+	// The group is done once the next poll sees the cancellation.
+	asrt.NoError(m.Poll(ctx))
+	asrt.Equal(GroupDone, m.Groups()[0].State)
+}
+
+func TestOCORejectsDoubleSell(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	m, err := NewOrderGroupManager(newTestClient(t, f), "")
+	asrt.NoError(err)
+
+	_, err = m.OCO(context.Background(),
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "limit", Price: 110, Quantity: 10},
+		&RhOrder{Symbol: "abc", Side: "sell", Type: "limit", Price: 120, Quantity: 10},
+	)
+	asrt.Error(err)
+	asrt.Empty(f.posted)
+	asrt.Empty(m.Groups())
+}
+
+func TestOCOCancelsSubmittedLegOnFailure(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	f.reject = 2
+	m, err := NewOrderGroupManager(newTestClient(t, f), "")
+	asrt.NoError(err)
+
+	g, err := m.OCO(context.Background(),
+		&RhOrder{Symbol: "ABC", Side: "sell", Type: "limit", Price: 110, Quantity: 10},
+		&RhOrder{Symbol: "ABC", Side: "buy", Type: "limit", Price: 90, Quantity: 10},
+	)
+	asrt.Error(err)
+	asrt.Equal(GroupFailed, g.State)
+	asrt.Len(f.posted, 2)
+	asrt.Equal([]string{"order-1"}, f.cancelled)
+}
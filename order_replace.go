@@ -0,0 +1,128 @@
+package robinhood
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOrderFilled is returned by Replace when the original order filled
+// completely before it could be cancelled, leaving nothing to resubmit.
+var ErrOrderFilled = errors.New("order filled before it could be replaced")
+
+// ReplacePollInterval is how often Replace checks whether the cancellation of
+// the original order has taken effect.
+var ReplacePollInterval = time.Second
+
+// OrderChanges holds the modifications applied by Replace. Zero values leave
+// the corresponding field of the original order unchanged.
+type OrderChanges struct {
+	Price     float64
+	StopPrice float64
+	// Quantity is the new total size of the order, including anything the
+	// original has already filled.
+	Quantity    float64
+	TimeInForce TimeInForce
+}
+
+// Replace modifies a working order by cancelling it, waiting for the
+// cancellation to be confirmed, and submitting a new order for whatever
+// quantity remains unfilled. It returns the original order, updated to its
+// final state, along with the replacement.
+//
+// If the original fills while it is being cancelled, only the residual
+// quantity (if any) is resubmitted; when nothing remains, ErrOrderFilled is
+// returned along with a nil replacement.
+//
+// If the client is in DryRun mode, the cancellation is only logged, so
+// Replace does not wait for it and instead returns a dry-run replacement for
+// the quantity unfilled so far.
+func (o *OrderOutput) Replace(ctx context.Context, client *Client, changes OrderChanges) (*OrderOutput, *OrderOutput, error) {
+	if err := o.Cancel(ctx, client); err != nil {
+		// The cancel may have been refused because the order already
+		// finished; only give up if it is still working.
+		if uerr := o.Update(ctx, client); uerr != nil {
+			return o, nil, errors.Wrap(err, "could not cancel order")
+		}
+		if !o.IsDone() {
+			return o, nil, errors.Wrap(err, "could not cancel order")
+		}
+	}
+
+	if !client.DryRun {
+		if err := o.Wait(ctx, client, ReplacePollInterval); err != nil {
+			return o, nil, errors.Wrap(err, "could not confirm cancellation")
+		}
+	}
+
+	total := o.Quantity
+	if changes.Quantity != 0 {
+		total = changes.Quantity
+	}
+	residual := math.Round((total-o.CumulativeQuantity)*1e6) / 1e6
+	if residual <= 0 {
+		return o, nil, ErrOrderFilled
+	}
+
+	inst, err := client.GetInstrument(ctx, o.Instrument)
+	if err != nil {
+		return o, nil, errors.Wrap(err, "could not look up instrument")
+	}
+
+	ord := o.resubmission(inst, residual)
+	if changes.Price != 0 {
+		ord.Price = changes.Price
+	}
+	if changes.StopPrice != 0 {
+		ord.StopPrice = changes.StopPrice
+	}
+	if changes.TimeInForce != 0 {
+		ord.TimeInForce = strings.ToLower(changes.TimeInForce.String())
+	}
+
+	out, err := client.SubmitOrder(ctx, ord)
+	client.journal(ctx, JournalEntry{
+		Action:  JournalReplace,
+		Asset:   AssetEquity,
+		URL:     o.URL,
+		OrderID: o.ID,
+		RefID:   o.RefID,
+	}, out, err)
+	if err != nil {
+		return o, nil, errors.Wrap(err, "could not submit replacement order")
+	}
+	return o, out, nil
+}
+
+// resubmission returns an RhOrder matching the original order for the given
+// quantity.
+func (o *OrderOutput) resubmission(inst *Instrument, qty float64) *RhOrder {
+	ord := &RhOrder{
+		Account:                o.Account,
+		ExtendedHours:          o.ExtendedHours,
+		Instrument:             o.Instrument,
+		Price:                  o.Price,
+		Quantity:               qty,
+		Side:                   o.Side,
+		Symbol:                 inst.Symbol,
+		TimeInForce:            o.TimeInForce,
+		Trigger:                o.Trigger,
+		Type:                   o.Type,
+		StopPrice:              o.StopPrice,
+		OverrideDayTradeChecks: o.OverrideDayTradeChecks,
+		OverrideDtbpChecks:     o.OverrideDtbpChecks,
+		MarketHours:            "regular_hours",
+	}
+	if o.ExtendedHours {
+		ord.MarketHours = "extended_hours"
+	}
+	if o.TrailingPeg.Type != "" {
+		ord.TrailingPeg = &TrailPeg{Type: o.TrailingPeg.Type}
+		ord.TrailingPeg.Price.Amount = o.TrailingPeg.Price.Amount
+		ord.TrailingPeg.Price.CurrencyCode = o.TrailingPeg.Price.CurrencyCode
+	}
+	return ord
+}
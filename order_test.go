@@ -0,0 +1,73 @@
+package robinhood
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newJournaledClient(t *testing.T, f *fakeOrders) (*Client, *FileRefJournal) {
+	c := newTestClient(t, f)
+	j := &FileRefJournal{Path: filepath.Join(t.TempDir(), "refs.json")}
+	c.RefJournal = j
+	return c, j
+}
+
+func TestSubmitOrderFindsDroppedOrder(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	f.drop, f.dropCreated = 1, true
+	c, j := newJournaledClient(t, f)
+
+	out, err := c.SubmitOrder(context.Background(), &RhOrder{Symbol: "ABC", Side: "buy", Type: "market", Quantity: 1})
+	asrt.NoError(err)
+	if asrt.NotNil(out) {
+		asrt.Equal("order-1", out.ID)
+	}
+	asrt.Len(f.posted, 1, "an order that was created is never resent")
+
+	pending, err := j.Pending()
+	asrt.NoError(err)
+	asrt.Empty(pending)
+}
+
+func TestSubmitOrderRetriesUncreatedOrder(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	f.drop = 1
+	c, j := newJournaledClient(t, f)
+
+	out, err := c.SubmitOrder(context.Background(), &RhOrder{Symbol: "ABC", Side: "buy", Type: "market", Quantity: 1})
+	asrt.NoError(err)
+	if asrt.NotNil(out) {
+		asrt.Equal("order-2", out.ID)
+	}
+	if asrt.Len(f.posted, 2) {
+		asrt.NotEmpty(f.posted[0].RefID)
+		asrt.Equal(f.posted[0].RefID, f.posted[1].RefID)
+	}
+
+	pending, err := j.Pending()
+	asrt.NoError(err)
+	asrt.Empty(pending)
+}
+
+func TestSubmitOrderRejected(t *testing.T) {
+	asrt := assert.New(t)
+
+	f := newFakeOrders()
+	f.reject = 1
+	c, j := newJournaledClient(t, f)
+
+	_, err := c.SubmitOrder(context.Background(), &RhOrder{Symbol: "ABC", Side: "buy", Type: "market", Quantity: 1})
+	asrt.IsType(ErrorMap{}, err)
+	asrt.Len(f.posted, 1, "a rejected order is not retried")
+
+	pending, err := j.Pending()
+	asrt.NoError(err)
+	asrt.Empty(pending)
+}
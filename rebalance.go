@@ -0,0 +1,311 @@
+package robinhood
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RebalanceOpts tunes PlanRebalance.
+type RebalanceOpts struct {
+	// DriftThreshold is how far, in absolute weight, a holding may stray
+	// from its target before it is traded, e.g. 0.02 for two percentage
+	// points.
+	DriftThreshold float64
+	// CashBuffer is the amount of cash, in dollars, to leave uninvested.
+	CashBuffer float64
+}
+
+// A RebalanceHolding is the current and target allocation of one symbol.
+type RebalanceHolding struct {
+	Symbol     string
+	Instrument *Instrument
+	Quantity   float64
+	Price      float64
+	Value      float64
+	Weight     float64
+	Target     float64
+}
+
+// Drift is how far the holding's weight is from its target.
+func (h RebalanceHolding) Drift() float64 {
+	return h.Weight - h.Target
+}
+
+// A RebalanceTrade is one order in a RebalancePlan.
+type RebalanceTrade struct {
+	Symbol     string
+	Instrument *Instrument
+	Side       OrderSide
+	Quantity   float64
+	Price      float64
+}
+
+// Value is the approximate dollar value of the trade.
+func (t RebalanceTrade) Value() float64 {
+	return t.Quantity * t.Price
+}
+
+// A RebalancePlan is the set of trades that moves a portfolio to its target
+// weights. Sells are listed before buys.
+type RebalancePlan struct {
+	// Value is the total value under management: the targeted holdings
+	// plus cash, less the cash buffer.
+	Value float64
+	// Cash is the account's cash. Buying power is not used, since on a
+	// margin account it includes borrowing.
+	Cash     float64
+	Holdings []RebalanceHolding
+	Trades   []RebalanceTrade
+}
+
+// PlanRebalance compares the current positions in the target symbols with
+// the target weights, which map symbols to fractions of the portfolio and may
+// sum to less than one to hold the rest in cash. Positions in symbols without
+// a target are left alone and not counted.
+//
+// Only holdings whose drift exceeds the threshold are traded. Quantities are
+// whole shares unless the instrument is fractionally tradable, and buys are
+// scaled down if sale proceeds and cash cannot cover them.
+func (c *Client) PlanRebalance(ctx context.Context, targets map[string]float64, opts RebalanceOpts) (*RebalancePlan, error) {
+	var sum float64
+	want := map[string]float64{}
+	for sym, w := range targets {
+		if w < 0 {
+			return nil, fmt.Errorf("negative target weight for %s", sym)
+		}
+		want[strings.ToUpper(sym)] = w
+		sum += w
+	}
+	if sum > 1+1e-9 {
+		return nil, fmt.Errorf("target weights sum to %.4f, more than 1", sum)
+	}
+
+	accts, err := c.GetAccounts(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get account")
+	}
+	if len(accts) < 1 {
+		return nil, errors.New("no account")
+	}
+
+	ps, err := c.GetPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get positions")
+	}
+
+	hs := map[string]*RebalanceHolding{}
+	for _, p := range ps {
+		inst, err := c.GetInstrument(ctx, p.Instrument)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not look up instrument")
+		}
+		if _, ok := want[inst.Symbol]; !ok {
+			continue
+		}
+		hs[inst.Symbol] = &RebalanceHolding{Symbol: inst.Symbol, Instrument: inst, Quantity: p.Quantity}
+	}
+
+	syms := make([]string, 0, len(want))
+	for sym := range want {
+		syms = append(syms, sym)
+		if _, ok := hs[sym]; ok {
+			continue
+		}
+		inst, err := c.GetInstrumentForSymbol(ctx, sym)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not look up %s", sym)
+		}
+		hs[sym] = &RebalanceHolding{Symbol: sym, Instrument: inst}
+	}
+	sort.Strings(syms)
+
+	qs, err := c.GetQuote(ctx, syms...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get quotes")
+	}
+	for _, q := range qs {
+		if h, ok := hs[q.Symbol]; ok {
+			h.Price = q.Price()
+		}
+	}
+
+	plan := &RebalancePlan{Cash: accts[0].Cash}
+	plan.Value = plan.Cash - opts.CashBuffer
+	for _, sym := range syms {
+		h := hs[sym]
+		if h.Price <= 0 {
+			return nil, fmt.Errorf("no price for %s", sym)
+		}
+		h.Value = h.Quantity * h.Price
+		plan.Value += h.Value
+	}
+	if plan.Value <= 0 {
+		return nil, errors.New("nothing to allocate after the cash buffer")
+	}
+
+	var sells, buys []RebalanceTrade
+	for _, sym := range syms {
+		h := hs[sym]
+		h.Weight = h.Value / plan.Value
+		h.Target = want[sym]
+		plan.Holdings = append(plan.Holdings, *h)
+
+		if math.Abs(h.Drift()) <= opts.DriftThreshold {
+			continue
+		}
+
+		qty := roundShares(h.Instrument, math.Abs(h.Target*plan.Value-h.Value)/h.Price)
+		t := RebalanceTrade{Symbol: sym, Instrument: h.Instrument, Price: h.Price, Quantity: qty}
+		if h.Drift() > 0 {
+			t.Side = Sell
+			t.Quantity = math.Min(qty, h.Quantity)
+			sells = append(sells, t)
+		} else {
+			t.Side = Buy
+			buys = append(buys, t)
+		}
+	}
+
+	// Buys may spend only what is in hand plus what the sells raise.
+	avail := plan.Cash - opts.CashBuffer
+	for _, t := range sells {
+		avail += t.Value()
+	}
+	var spend float64
+	for _, t := range buys {
+		spend += t.Value()
+	}
+	if spend > avail && spend > 0 {
+		scale := math.Max(avail, 0) / spend
+		for i := range buys {
+			buys[i].Quantity = roundShares(buys[i].Instrument, buys[i].Quantity*scale)
+		}
+	}
+
+	for _, t := range append(sells, buys...) {
+		if t.Quantity > 0 {
+			plan.Trades = append(plan.Trades, t)
+		}
+	}
+	return plan, nil
+}
+
+// roundShares rounds a quantity down to what the instrument can trade.
+func roundShares(inst *Instrument, q float64) float64 {
+	if inst.FractionalTradability == Tradable {
+		return math.Floor(q*1e6) / 1e6
+	}
+	return math.Floor(q)
+}
+
+// String renders the plan as a table of holdings followed by the trades.
+func (p *RebalancePlan) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "SYMBOL\tQUANTITY\tPRICE\tVALUE\tWEIGHT\tTARGET\tDRIFT\n")
+	for _, h := range p.Holdings {
+		fmt.Fprintf(w, "%s\t%.6g\t%.2f\t%.2f\t%.2f%%\t%.2f%%\t%+.2f%%\n",
+			h.Symbol, h.Quantity, h.Price, h.Value, h.Weight*100, h.Target*100, h.Drift()*100)
+	}
+	fmt.Fprintf(w, "\nCASH %.2f\tVALUE %.2f\n\n", p.Cash, p.Value)
+
+	if len(p.Trades) == 0 {
+		fmt.Fprintf(w, "no trades\n")
+	} else {
+		fmt.Fprintf(w, "SIDE\tSYMBOL\tQUANTITY\tPRICE\tVALUE\n")
+		for _, t := range p.Trades {
+			fmt.Fprintf(w, "%s\t%s\t%.6g\t%.2f\t%.2f\n",
+				strings.ToLower(t.Side.String()), t.Symbol, t.Quantity, t.Price, t.Value())
+		}
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+// Execute submits the plan's trades as market orders. Sells are submitted
+// first and must all finish, polling every poll interval, before any buy is
+// submitted, so that their proceeds are available.
+//
+// Sells that are rejected, cancelled or only partly filled raise less than
+// planned, so the buys are scaled down by the shortfall, valued at the
+// planned prices. If that leaves nothing to buy, no buy is submitted and an
+// error is returned.
+func (p *RebalancePlan) Execute(ctx context.Context, c *Client, poll time.Duration) ([]*OrderOutput, error) {
+	var outs []*OrderOutput
+
+	submit := func(trades []RebalanceTrade) error {
+		var placed []*OrderOutput
+		for _, t := range trades {
+			ord := c.CreateOrder(t.Instrument)
+			ord.Side = strings.ToLower(t.Side.String())
+			ord.Quantity = t.Quantity
+			ord.Price = t.Price
+
+			out, err := c.SubmitOrder(ctx, ord)
+			if err != nil {
+				return errors.Wrapf(err, "could not %s %s", ord.Side, t.Symbol)
+			}
+			outs = append(outs, out)
+			placed = append(placed, out)
+		}
+
+		for _, o := range placed {
+			if err := o.Wait(ctx, c, poll); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var sells, buys []RebalanceTrade
+	for _, t := range p.Trades {
+		if t.Side == Sell {
+			sells = append(sells, t)
+		} else {
+			buys = append(buys, t)
+		}
+	}
+
+	if err := submit(sells); err != nil {
+		return outs, err
+	}
+
+	var planned, shortfall, spend float64
+	for i, t := range sells {
+		filled := outs[i].CumulativeQuantity
+		if outs[i].State == OrderStateDryRun {
+			filled = outs[i].Quantity
+		}
+		planned += t.Value()
+		shortfall += (t.Quantity - filled) * t.Price
+	}
+	for _, t := range buys {
+		spend += t.Value()
+	}
+
+	if shortfall > 0.005 && spend > 0 {
+		scale := math.Max(spend-shortfall, 0) / spend
+		var kept []RebalanceTrade
+		for _, t := range buys {
+			t.Quantity = roundShares(t.Instrument, t.Quantity*scale)
+			if t.Quantity > 0 {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			return outs, fmt.Errorf("sells raised %.2f of %.2f planned, leaving nothing to buy", planned-shortfall, planned)
+		}
+		buys = kept
+	}
+	return outs, submit(buys)
+}
@@ -0,0 +1,140 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// A RefJournal durably records orders that are about to be submitted, keyed
+// by ref ID, so that after a crash or an ambiguous failure it is possible to
+// tell whether each one was created.
+type RefJournal interface {
+	// Begin records that the order with the given ref ID and payload is
+	// about to be sent.
+	Begin(refID string, payload []byte) error
+	// Resolve records the outcome for a ref ID: the ID of the order
+	// created for it, or "" if none was.
+	Resolve(refID, orderID string) error
+	// Pending returns the entries that have begun but not been resolved.
+	Pending() ([]RefEntry, error)
+}
+
+// A RefEntry is an order submission recorded in a RefJournal.
+type RefEntry struct {
+	RefID     string          `json:"ref_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FileRefJournal is a RefJournal kept in a json file. Resolved entries are
+// removed, so the file holds only submissions whose outcome is unknown.
+type FileRefJournal struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Begin implements RefJournal.
+func (j *FileRefJournal) Begin(refID string, payload []byte) error {
+	return j.update(func(es map[string]RefEntry) {
+		es[refID] = RefEntry{RefID: refID, Payload: payload, CreatedAt: time.Now()}
+	})
+}
+
+// Resolve implements RefJournal.
+func (j *FileRefJournal) Resolve(refID, orderID string) error {
+	return j.update(func(es map[string]RefEntry) {
+		delete(es, refID)
+	})
+}
+
+// Pending implements RefJournal.
+func (j *FileRefJournal) Pending() ([]RefEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	es := map[string]RefEntry{}
+	if err := loadJSON(j.Path, &es); err != nil {
+		return nil, err
+	}
+
+	out := make([]RefEntry, 0, len(es))
+	for _, e := range es {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, k int) bool {
+		return out[i].CreatedAt.Before(out[k].CreatedAt)
+	})
+	return out, nil
+}
+
+func (j *FileRefJournal) update(f func(map[string]RefEntry)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	es := map[string]RefEntry{}
+	if err := loadJSON(j.Path, &es); err != nil {
+		return err
+	}
+	f(es)
+	return saveJSON(j.Path, es)
+}
+
+// resolveRef records the outcome of a submission in the client's RefJournal,
+// if it has one. The outcome is known by then, so a failure is only logged;
+// the entry stays pending until ReconcileRefJournal resolves it.
+func (c *Client) resolveRef(refID, orderID string) {
+	if c.RefJournal == nil {
+		return
+	}
+	if err := c.RefJournal.Resolve(refID, orderID); err != nil {
+		c.logf("robinhood: could not update order journal for %s: %v", refID, err)
+	}
+}
+
+// ReconcileRefJournal resolves every pending entry in the client's
+// RefJournal by searching recent orders for its ref ID. It returns the orders
+// that were found; entries with no matching order are resolved as never
+// created.
+func (c *Client) ReconcileRefJournal(ctx context.Context) ([]*OrderOutput, error) {
+	if c.RefJournal == nil {
+		return nil, nil
+	}
+
+	es, err := c.RefJournal.Pending()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read order journal")
+	}
+	if len(es) == 0 {
+		return nil, nil
+	}
+
+	recent, err := c.RecentOrders(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list recent orders")
+	}
+	byRef := map[string]*OrderOutput{}
+	for i := range recent {
+		byRef[recent[i].RefID] = &recent[i]
+	}
+
+	var found []*OrderOutput
+	var errs error
+	for _, e := range es {
+		id := ""
+		if o, ok := byRef[e.RefID]; ok {
+			found = append(found, o)
+			id = o.ID
+		}
+		if err := c.RefJournal.Resolve(e.RefID, id); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return found, errs
+}
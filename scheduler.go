@@ -0,0 +1,292 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// A SessionEvent is a point in the trading day against which an order can be
+// scheduled.
+type SessionEvent string
+
+// Session events known to OrderScheduler.
+const (
+	// SessionMarketOpen is the opening bell of regular trading.
+	SessionMarketOpen SessionEvent = "market_open"
+	// SessionMarketClose is the closing bell. Use a negative offset to act
+	// shortly before the close.
+	SessionMarketClose SessionEvent = "market_close"
+	// SessionExtendedOpen is when Robinhood extended-hours trading begins.
+	SessionExtendedOpen SessionEvent = "extended_open"
+	// SessionExtendedClose is when Robinhood extended-hours trading ends.
+	SessionExtendedClose SessionEvent = "extended_close"
+)
+
+// Next returns the next occurrence of the event, looking up the NYSE's hours
+// so that holidays are skipped and early closes are honoured.
+func (e SessionEvent) Next(ctx context.Context, c *Client) (time.Time, error) {
+	return e.next(ctx, c, time.Now())
+}
+
+// maxSessionLookahead is how many days Next searches for an open market.
+const maxSessionLookahead = 10
+
+func (e SessionEvent) next(ctx context.Context, c *Client, now time.Time) (time.Time, error) {
+	if _, ok := e.in(&MarketHours{}); !ok {
+		return time.Time{}, fmt.Errorf("unknown session event %q", e)
+	}
+
+	day := now.In(nyLoc())
+	for i := 0; i < maxSessionLookahead; i++ {
+		h, err := c.GetMarketHours(ctx, MarketNYSE, Date{day})
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "could not get market hours for %s", Date{day})
+		}
+		if t, _ := e.in(h); h.IsOpen && t.After(now) {
+			return t, nil
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, fmt.Errorf("no %s in the next %d days", e, maxSessionLookahead)
+}
+
+// in returns the time of the event within the given hours, and false if the
+// event is unknown.
+func (e SessionEvent) in(h *MarketHours) (time.Time, bool) {
+	switch e {
+	case SessionMarketOpen:
+		return h.OpensAt, true
+	case SessionMarketClose:
+		return h.ClosesAt, true
+	case SessionExtendedOpen:
+		return h.ExtendedOpensAt, true
+	case SessionExtendedClose:
+		return h.ExtendedClosesAt, true
+	}
+	return time.Time{}, false
+}
+
+// A ScheduledOrder is an order held by an OrderScheduler until its time
+// arrives.
+type ScheduledOrder struct {
+	ID    string   `json:"id"`
+	Order *RhOrder `json:"order"`
+	// Event and Offset record the session event the order was scheduled
+	// against, if any.
+	Event  SessionEvent  `json:"event,omitempty"`
+	Offset time.Duration `json:"offset,omitempty"`
+	// At is when the order will be submitted.
+	At        time.Time `json:"at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// An OrderScheduler holds orders and submits them at a wall-clock time or at
+// a point relative to a market session. If Path is set, pending orders are
+// saved there whenever they change, and a scheduler created with the same
+// path picks them up again.
+//
+// An order is removed from the pending set before it is submitted, so a crash
+// at the wrong moment may lose an order but will never submit it twice.
+type OrderScheduler struct {
+	Client *Client
+	Path   string
+	// OnSubmit, if set, is called with each order the scheduler submits and
+	// the result of submitting it.
+	OnSubmit func(so *ScheduledOrder, out *OrderOutput, err error)
+
+	mu      sync.Mutex
+	pending map[string]*ScheduledOrder
+	wake    chan struct{}
+}
+
+// NewOrderScheduler returns a scheduler for the client, restoring any pending
+// orders previously saved at path. An empty path keeps them in memory only.
+func NewOrderScheduler(c *Client, path string) (*OrderScheduler, error) {
+	s := &OrderScheduler{
+		Client:  c,
+		Path:    path,
+		pending: map[string]*ScheduledOrder{},
+		wake:    make(chan struct{}, 1),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	var sos []*ScheduledOrder
+	if err := loadJSON(path, &sos); err != nil {
+		return nil, errors.Wrap(err, "could not load scheduled orders")
+	}
+	for _, so := range sos {
+		s.pending[so.ID] = so
+	}
+	return s, nil
+}
+
+// ScheduleAt holds the order until the given time.
+func (s *OrderScheduler) ScheduleAt(ord *RhOrder, at time.Time) (*ScheduledOrder, error) {
+	return s.add(&ScheduledOrder{Order: ord, At: at})
+}
+
+// ScheduleAtSession holds the order until the next occurrence of the session
+// event, shifted by offset. If the offset puts that time in the past, the
+// order is submitted as soon as the scheduler runs.
+func (s *OrderScheduler) ScheduleAtSession(ctx context.Context, ord *RhOrder, ev SessionEvent, offset time.Duration) (*ScheduledOrder, error) {
+	at, err := ev.Next(ctx, s.Client)
+	if err != nil {
+		return nil, err
+	}
+	return s.add(&ScheduledOrder{
+		Order:  ord,
+		Event:  ev,
+		Offset: offset,
+		At:     at.Add(offset),
+	})
+}
+
+func (s *OrderScheduler) add(so *ScheduledOrder) (*ScheduledOrder, error) {
+	so.ID = uuid.New().String()
+	so.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[so.ID] = so
+	if err := s.save(); err != nil {
+		delete(s.pending, so.ID)
+		return nil, errors.Wrap(err, "could not save scheduled orders")
+	}
+	s.poke()
+
+	out := *so
+	return &out, nil
+}
+
+// Cancel removes a pending order so that it is never submitted.
+func (s *OrderScheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	so, ok := s.pending[id]
+	if !ok {
+		return fmt.Errorf("no scheduled order %q", id)
+	}
+
+	delete(s.pending, id)
+	if err := s.save(); err != nil {
+		s.pending[id] = so
+		return errors.Wrap(err, "could not save scheduled orders")
+	}
+	s.poke()
+	return nil
+}
+
+// Pending returns the orders waiting to be submitted, soonest first.
+func (s *OrderScheduler) Pending() []*ScheduledOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*ScheduledOrder, 0, len(s.pending))
+	for _, so := range s.sorted() {
+		so := *so
+		out = append(out, &so)
+	}
+	return out
+}
+
+// Run submits pending orders as they come due until the context is
+// cancelled.
+func (s *OrderScheduler) Run(ctx context.Context) error {
+	for {
+		due, wait, err := s.due(time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, so := range due {
+			out, err := s.Client.SubmitOrder(ctx, so.Order)
+			if err != nil {
+				s.Client.logf("robinhood: scheduled order %s: %v", so.ID, err)
+			}
+			if s.OnSubmit != nil {
+				s.OnSubmit(so, out, err)
+			}
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-s.wake:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// maxSchedulerWait bounds how long Run sleeps between checks, so that clock
+// changes and system sleep do not delay orders indefinitely.
+const maxSchedulerWait = time.Minute
+
+// due removes and returns the orders due at now, along with how long to wait
+// before the next one is.
+func (s *OrderScheduler) due(now time.Time) ([]*ScheduledOrder, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*ScheduledOrder
+	wait := maxSchedulerWait
+	for _, so := range s.sorted() {
+		if so.At.After(now) {
+			if d := so.At.Sub(now); d < wait {
+				wait = d
+			}
+			break
+		}
+		due = append(due, so)
+		delete(s.pending, so.ID)
+	}
+
+	if len(due) > 0 {
+		if err := s.save(); err != nil {
+			for _, so := range due {
+				s.pending[so.ID] = so
+			}
+			return nil, 0, errors.Wrap(err, "could not save scheduled orders")
+		}
+	}
+	return due, wait, nil
+}
+
+// poke wakes Run so it can reconsider the next due time.
+func (s *OrderScheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *OrderScheduler) sorted() []*ScheduledOrder {
+	sos := make([]*ScheduledOrder, 0, len(s.pending))
+	for _, so := range s.pending {
+		sos = append(sos, so)
+	}
+	sort.Slice(sos, func(i, j int) bool {
+		return sos[i].At.Before(sos[j].At)
+	})
+	return sos
+}
+
+func (s *OrderScheduler) save() error {
+	if s.Path == "" {
+		return nil
+	}
+	return saveJSON(s.Path, s.sorted())
+}
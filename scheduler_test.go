@@ -0,0 +1,54 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionEventNext(t *testing.T) {
+	asrt := assert.New(t)
+
+	at := func(d, hm string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", d+" "+hm, nyLoc())
+		asrt.NoError(err)
+		return t
+	}
+
+	// July 3rd 2024 closed early for Independence Day, and the 4th was a
+	// holiday.
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/markets/XNYS/hours/"), "/")
+		h := MarketHours{IsOpen: true, OpensAt: at(d, "09:30"), ClosesAt: at(d, "16:00"),
+			ExtendedOpensAt: at(d, "07:00"), ExtendedClosesAt: at(d, "20:00")}
+		switch d {
+		case "2024-07-03":
+			h.ClosesAt = at(d, "13:00")
+			h.ExtendedClosesAt = at(d, "17:00")
+		case "2024-07-04":
+			h = MarketHours{}
+		}
+		json.NewEncoder(w).Encode(h)
+	}))
+	ctx := context.Background()
+
+	next, err := SessionMarketClose.next(ctx, c, at("2024-07-03", "10:00"))
+	asrt.NoError(err)
+	asrt.True(at("2024-07-03", "13:00").Equal(next))
+
+	next, err = SessionMarketClose.next(ctx, c, at("2024-07-03", "14:00"))
+	asrt.NoError(err)
+	asrt.True(at("2024-07-05", "16:00").Equal(next))
+
+	next, err = SessionMarketOpen.next(ctx, c, at("2024-07-03", "10:00"))
+	asrt.NoError(err)
+	asrt.True(at("2024-07-05", "09:30").Equal(next))
+
+	_, err = SessionEvent("lunch").next(ctx, c, at("2024-07-03", "10:00"))
+	asrt.Error(err)
+}
@@ -0,0 +1,53 @@
+package robinhood
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// loadJSON decodes the file at p into v. A missing file is not an error and
+// leaves v untouched.
+func loadJSON(p string, v interface{}) error {
+	bs, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(bs) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bs, v)
+}
+
+// saveJSON replaces the file at p with the json encoding of v. The data is
+// written to a temporary file first so a crash never leaves a partial file
+// behind.
+func saveJSON(p string, v interface{}) error {
+	bs, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(p), 0750); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(path.Dir(p), path.Base(p)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(bs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}